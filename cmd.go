@@ -2,18 +2,23 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/rushairer/rtag/pkg/rtag"
 	"github.com/spf13/cobra"
 )
 
-const rtagFile = ".rtag"
-
 var rootCmd *cobra.Command
 var initCmd *cobra.Command
 var addCmd *cobra.Command
@@ -21,14 +26,85 @@ var pushCmd *cobra.Command
 var listCmd *cobra.Command
 var rmCmd *cobra.Command
 var langCmd *cobra.Command
-
+var rollbackCmd *cobra.Command
+var yankCmd *cobra.Command
+var historyCmd *cobra.Command
+var latestCmd *cobra.Command
+var promoteCmd *cobra.Command
+var trainCmd *cobra.Command
+var gomodCmd *cobra.Command
+var gomodDiscoverCmd *cobra.Command
+var gomodTagCmd *cobra.Command
+var importCmd *cobra.Command
+var migrateTagsCmd *cobra.Command
+var wsCmd *cobra.Command
+var wsListCmd *cobra.Command
+var wsStatusCmd *cobra.Command
+var wsPushCmd *cobra.Command
+var lockCmd *cobra.Command
+var lockStatusCmd *cobra.Command
+var lockBreakCmd *cobra.Command
+var policyCmd *cobra.Command
+var policyCheckCmd *cobra.Command
+var freezeCmd *cobra.Command
+var freezeListCmd *cobra.Command
+var notifyCmd *cobra.Command
+var notifyTestCmd *cobra.Command
+
+var rtagFileFlag string
+var gitTimeout time.Duration
+var pushRetries int
+var initDiscover bool
 var pushAll bool
+var pushFloating bool
+var pushCascade bool
+var pushLock bool
+var overrideFreeze bool
+var freezeReason string
+var freezeDays int
+var pushReportFile string
+var trainReportFile string
+var notifyURL string
+var notifyAll bool
+var notifyWebhook int
+var rollbackTo string
+var yankReason string
+var promoteTo string
+var goModBump string
+var importPattern string
+var migrateFrom string
+var migrateTo string
+var migrateDeleteOld bool
+var migrateDryRun bool
+var migrateReport string
+var workspaceFile string
+var workspaceReportFile string
+
+// exitCode is the status rtag exits with. Commands print their outcome, those
+// CI relies on to gate releases set it when a check fails or a release is refused.
+var exitCode int
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels the running git operations, the tags the run created
+	// but did not push are then cleaned up before exiting
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	runContext = ctx
+	disableGitPrompts()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if ctx.Err() != nil {
+		stop()
+		cleanupInterruptedRun()
+		os.Exit(130)
+	}
+	if err != nil {
 		log.Fatalf("Error executing root command: %v", err)
 		os.Exit(1)
 	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
 
 func init() {
@@ -57,14 +133,14 @@ func initializeCommands() {
 	}
 
 	addCmd = &cobra.Command{
-		Use:   "add [tag]",
+		Use:   "add [tag...]",
 		Short: T().AddShort,
 		Long:  T().AddLong,
 		Run:   runAdd,
 	}
 
 	pushCmd = &cobra.Command{
-		Use:   "push [tag]",
+		Use:   "push [tag|@group|pattern...]",
 		Short: T().PushShort,
 		Long:  T().PushLong,
 		Run:   runPush,
@@ -79,10 +155,10 @@ func initializeCommands() {
 	}
 
 	rmCmd = &cobra.Command{
-		Use:   "rm [tag]",
+		Use:   "rm [tag|@group|pattern...]",
 		Short: T().RmShort,
 		Long:  T().RmLong,
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		Run:   runRm,
 	}
 
@@ -95,15 +171,227 @@ func initializeCommands() {
 		Run:   runLang,
 	}
 
-	pushCmd.Flags().BoolVar(&pushAll, "all", false, T().PushAllFlag)
+	rollbackCmd = &cobra.Command{
+		Use:   "rollback [tag]",
+		Short: T().RollbackShort,
+		Long:  T().RollbackLong,
+		Args:  cobra.ExactArgs(1),
+		Run:   runRollback,
+	}
+
+	yankCmd = &cobra.Command{
+		Use:   "yank [release]",
+		Short: T().YankShort,
+		Long:  T().YankLong,
+		Args:  cobra.ExactArgs(1),
+		Run:   runYank,
+	}
 
-	rootCmd.AddCommand(initCmd, addCmd, pushCmd, listCmd, rmCmd, langCmd)
+	historyCmd = &cobra.Command{
+		Use:   "history [tag]",
+		Short: T().HistoryShort,
+		Long:  T().HistoryLong,
+		Args:  cobra.ExactArgs(1),
+		Run:   runHistory,
+	}
+
+	latestCmd = &cobra.Command{
+		Use:   "latest [tag]",
+		Short: T().LatestShort,
+		Long:  T().LatestLong,
+		Args:  cobra.ExactArgs(1),
+		Run:   runLatest,
+	}
+
+	promoteCmd = &cobra.Command{
+		Use:   "promote [tag]",
+		Short: T().PromoteShort,
+		Long:  T().PromoteLong,
+		Args:  cobra.ExactArgs(1),
+		Run:   runPromote,
+	}
+
+	trainCmd = &cobra.Command{
+		Use:   "train [group]",
+		Short: T().TrainShort,
+		Long:  T().TrainLong,
+		Args:  cobra.ExactArgs(1),
+		Run:   runTrain,
+	}
+
+	gomodCmd = &cobra.Command{
+		Use:   "gomod",
+		Short: T().GoModShort,
+		Long:  T().GoModLong,
+	}
+
+	gomodDiscoverCmd = &cobra.Command{
+		Use:   "discover",
+		Short: T().GoModDiscoverShort,
+		Long:  T().GoModDiscoverLong,
+		Args:  cobra.NoArgs,
+		Run:   runGoModDiscover,
+	}
+
+	gomodTagCmd = &cobra.Command{
+		Use:   "tag [module dir] [version]",
+		Short: T().GoModTagShort,
+		Long:  T().GoModTagLong,
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   runGoModTag,
+	}
+
+	importCmd = &cobra.Command{
+		Use:   "import",
+		Short: T().ImportShort,
+		Long:  T().ImportLong,
+		Args:  cobra.NoArgs,
+		Run:   runImport,
+	}
+
+	migrateTagsCmd = &cobra.Command{
+		Use:   "migrate-tags",
+		Short: T().MigrateTagsShort,
+		Long:  T().MigrateTagsLong,
+		Args:  cobra.NoArgs,
+		Run:   runMigrateTags,
+	}
+
+	wsCmd = &cobra.Command{
+		Use:   "ws",
+		Short: T().WorkspaceShort,
+		Long:  T().WorkspaceLong,
+	}
+
+	wsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: T().WorkspaceListShort,
+		Args:  cobra.NoArgs,
+		Run:   runWorkspaceList,
+	}
+
+	wsStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: T().WorkspaceStatusShort,
+		Args:  cobra.NoArgs,
+		Run:   runWorkspaceStatus,
+	}
+
+	wsPushCmd = &cobra.Command{
+		Use:   "push",
+		Short: T().WorkspacePushShort,
+		Args:  cobra.NoArgs,
+		Run:   runWorkspacePush,
+	}
+
+	lockCmd = &cobra.Command{
+		Use:   "lock",
+		Short: T().LockShort,
+	}
+
+	lockStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: T().LockStatusShort,
+		Args:  cobra.NoArgs,
+		Run:   runLockStatus,
+	}
+
+	lockBreakCmd = &cobra.Command{
+		Use:   "break [tag...]",
+		Short: T().LockBreakShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runLockBreak,
+	}
+
+	policyCmd = &cobra.Command{
+		Use:   "policy",
+		Short: T().PolicyShort,
+	}
+
+	policyCheckCmd = &cobra.Command{
+		Use:   "check [tag|@group|pattern...]",
+		Short: T().PolicyCheckShort,
+		Run:   runPolicyCheck,
+	}
+
+	freezeCmd = &cobra.Command{
+		Use:   "freeze",
+		Short: T().FreezeShort,
+	}
+
+	freezeListCmd = &cobra.Command{
+		Use:   "list",
+		Short: T().FreezeListShort,
+		Args:  cobra.NoArgs,
+		Run:   runFreezeList,
+	}
+
+	notifyCmd = &cobra.Command{
+		Use:   "notify",
+		Short: T().NotifyShort,
+	}
+
+	notifyTestCmd = &cobra.Command{
+		Use:   "test",
+		Short: T().NotifyTestShort,
+		Args:  cobra.NoArgs,
+		Run:   runNotifyTest,
+	}
+
+	rootCmd.PersistentFlags().StringVar(&rtagFileFlag, "file", "", T().FileFlag)
+	rootCmd.PersistentFlags().DurationVar(&gitTimeout, "timeout", 0, T().TimeoutFlag)
+	rootCmd.PersistentFlags().IntVar(&pushRetries, "retries", rtag.DefaultRetryPolicy.Retries, T().RetriesFlag)
+	initCmd.Flags().BoolVar(&initDiscover, "discover", false, T().InitDiscoverFlag)
+	pushCmd.Flags().BoolVar(&pushAll, "all", false, T().PushAllFlag)
+	pushCmd.Flags().BoolVar(&pushFloating, "floating", false, T().PushFloatingFlag)
+	pushCmd.Flags().BoolVar(&pushCascade, "cascade", false, T().PushCascadeFlag)
+	pushCmd.Flags().StringVar(&pushReportFile, "report", "", T().PushReportFlag)
+	trainCmd.Flags().StringVar(&trainReportFile, "report", "", T().TrainReportFlag)
+	// Every command creating release tags runs the same release pipeline
+	for _, releaseCmd := range []*cobra.Command{pushCmd, wsPushCmd, trainCmd, rollbackCmd, promoteCmd, gomodTagCmd} {
+		releaseCmd.Flags().BoolVar(&pushLock, "lock", false, T().PushLockFlag)
+		releaseCmd.Flags().BoolVar(&overrideFreeze, "override-freeze", false, T().OverrideFreezeFlag)
+		releaseCmd.Flags().StringVar(&freezeReason, "reason", "", T().FreezeReasonFlag)
+	}
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", T().RollbackToFlag)
+	yankCmd.Flags().StringVar(&yankReason, "reason", "", T().YankReasonFlag)
+	promoteCmd.Flags().StringVar(&promoteTo, "to", "", T().PromoteToFlag)
+	gomodTagCmd.Flags().StringVar(&goModBump, "bump", "patch", T().GoModBumpFlag)
+	importCmd.Flags().StringVar(&importPattern, "pattern", "", T().ImportPatternFlag)
+	migrateTagsCmd.Flags().StringVar(&migrateFrom, "from", "", T().MigrateFromFlag)
+	migrateTagsCmd.Flags().StringVar(&migrateTo, "to", "", T().MigrateToFlag)
+	migrateTagsCmd.Flags().BoolVar(&migrateDeleteOld, "delete-old", false, T().MigrateDeleteOldFlag)
+	migrateTagsCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, T().MigrateDryRunFlag)
+	migrateTagsCmd.Flags().StringVar(&migrateReport, "report", "", T().MigrateReportFlag)
+
+	wsCmd.PersistentFlags().StringVar(&workspaceFile, "workspace", defaultWorkspaceFile, T().WorkspaceFileFlag)
+	wsPushCmd.Flags().BoolVar(&pushAll, "all", false, T().PushAllFlag)
+	wsPushCmd.Flags().StringVar(&workspaceReportFile, "report", "", T().WorkspaceReportFlag)
+	freezeListCmd.Flags().IntVar(&freezeDays, "days", 30, T().FreezeDaysFlag)
+	notifyTestCmd.Flags().StringVar(&notifyURL, "url", "", T().NotifyURLFlag)
+	notifyTestCmd.Flags().BoolVar(&notifyAll, "all", false, T().NotifyAllFlag)
+	notifyTestCmd.Flags().IntVar(&notifyWebhook, "webhook", 1, T().NotifyWebhookFlag)
+
+	gomodCmd.AddCommand(gomodDiscoverCmd, gomodTagCmd)
+	wsCmd.AddCommand(wsListCmd, wsStatusCmd, wsPushCmd)
+	lockCmd.AddCommand(lockStatusCmd, lockBreakCmd)
+	policyCmd.AddCommand(policyCheckCmd)
+	freezeCmd.AddCommand(freezeListCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+
+	rootCmd.AddCommand(initCmd, addCmd, pushCmd, listCmd, rmCmd, langCmd, rollbackCmd, yankCmd, historyCmd, latestCmd, promoteCmd, trainCmd, gomodCmd, importCmd, migrateTagsCmd, wsCmd, lockCmd, policyCmd, freezeCmd, notifyCmd)
 }
 
 // Note: reinitializeCommands function removed to avoid circular dependency
 // Language changes will take effect on next command execution
 
 func runInit(cmd *cobra.Command, args []string) {
+	// 从仓库结构中发现 tags
+	if initDiscover {
+		interactiveDiscoverTags()
+		return
+	}
+
 	tags, err := readTags()
 	if err != nil {
 		fmt.Printf(T().ErrorReadingRtagFile+"\n", err)
@@ -126,12 +414,13 @@ func runAdd(cmd *cobra.Command, args []string) {
 		// 交互式模式
 		interactiveAddTag()
 	} else {
-		// 直接添加指定的 tag
-		tag := args[0]
-		if err := addTag(tag); err != nil {
-			fmt.Printf(T().AddTagFailed+"\n", err)
-		} else {
-			fmt.Printf(T().AddTagSuccess+"\n", tag)
+		// 直接添加指定的 tags
+		for _, tag := range args {
+			if err := addTag(tag); err != nil {
+				fmt.Printf(T().AddTagFailed+"\n", err)
+			} else {
+				fmt.Printf(T().AddTagSuccess+"\n", tag)
+			}
 		}
 	}
 }
@@ -139,71 +428,106 @@ func runAdd(cmd *cobra.Command, args []string) {
 func runPush(cmd *cobra.Command, args []string) {
 	if pushAll {
 		// 推送所有 tags
-		tags, err := readTags()
+		content, err := readTagFile()
 		if err != nil {
 			fmt.Printf(T().ReadTagsFailed+"\n", err)
 			return
 		}
 
-		if len(tags) == 0 {
+		if len(content.Tags) == 0 {
 			fmt.Println(T().NoTagsFound)
 			return
 		}
 
+		tags, err := orderTags(content, content.Tags)
+		if err != nil {
+			fmt.Printf(T().SelectTagsFailed+"\n", err)
+			return
+		}
+
 		pushTags(tags)
 	} else if len(args) > 0 {
-		// 推送指定的 tag
-		tag := args[0]
-		tags, err := readTags()
+		// 推送指定的 tags、分组或匹配的 tags
+		content, err := readTagFile()
 		if err != nil {
 			fmt.Printf(T().ReadTagsFailed+"\n", err)
 			return
 		}
 
-		// 检查 tag 是否存在
-		found := false
-		for _, t := range tags {
-			if t == tag {
-				found = true
-				break
-			}
+		tags, err := resolveSelectors(content, args)
+		if err != nil {
+			fmt.Printf(T().SelectTagsFailed+"\n", err)
+			return
+		}
+
+		// 级联发布依赖于所选 tags 的 tags
+		if pushCascade {
+			tags = withDependents(content, tags)
 		}
 
-		if !found {
-			fmt.Printf(T().TagNotExistInFile+"\n", tag)
+		tags, err = orderTags(content, tags)
+		if err != nil {
+			fmt.Printf(T().SelectTagsFailed+"\n", err)
 			return
 		}
 
-		pushTags([]string{tag})
+		pushTags(tags)
 	} else {
 		fmt.Println(T().SpecifyTagOrUseAll)
 	}
 }
 
 func runList(cmd *cobra.Command, args []string) {
-	tags, err := readTags()
+	content, err := readTagFile()
 	if err != nil {
 		fmt.Printf(T().ReadTagsFailed+"\n", err)
 		return
 	}
 
-	if len(tags) == 0 {
+	if len(content.Tags) == 0 {
 		fmt.Println(T().NoTagsFound)
 		return
 	}
 
 	fmt.Println(T().AllTags)
-	for _, tag := range tags {
+	for _, tag := range content.Tags {
 		fmt.Printf("  - %s\n", tag)
 	}
+
+	if len(content.Groups) > 0 {
+		fmt.Println(T().AllGroups)
+		for _, group := range content.Groups {
+			fmt.Printf("  - @%s: %s\n", group.Name, strings.Join(group.Tags, ", "))
+		}
+	}
+
+	if len(content.Dependencies) > 0 {
+		fmt.Println(T().AllDependencies)
+		for _, dependency := range content.Dependencies {
+			fmt.Printf("  - %s -> %s\n", dependency.Tag, strings.Join(dependency.DependsOn, ", "))
+		}
+	}
 }
 
 func runRm(cmd *cobra.Command, args []string) {
-	tag := args[0]
-	if err := removeTag(tag); err != nil {
+	content, err := readTagFile()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	tags, err := resolveSelectors(content, args)
+	if err != nil {
 		fmt.Printf(T().RemoveTagFailed+"\n", err)
-	} else {
-		fmt.Printf(T().RemoveTagSuccess+"\n", tag)
+		return
+	}
+
+	for _, tag := range tags {
+		if err := removeTag(tag); err != nil {
+			fmt.Printf(T().RemoveTagFailed+"\n", err)
+		} else {
+			fmt.Printf(T().RemoveTagSuccess+"\n", tag)
+		}
 	}
 }
 
@@ -264,83 +588,81 @@ func runLang(cmd *cobra.Command, args []string) {
 	fmt.Println(T().LanguagePreferenceSaved)
 }
 
+// readTagFile reads the .rtag file of the current project
+func readTagFile() (rtag.TagFile, error) {
+	content, err := rtag.ReadTagFile(rtagFilePath())
+	return content, localizeError(err)
+}
+
 func readTags() ([]string, error) {
-	file, err := os.Open(rtagFile)
+	content, err := readTagFile()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []string{}, nil
-		}
 		return nil, err
 	}
-	defer file.Close()
+	return content.Tags, nil
+}
 
-	var tags []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" {
-			tags = append(tags, line)
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
 		}
 	}
+	return false
+}
 
-	return tags, scanner.Err()
+// validateTagName rejects names that would be read back as a group or selector
+func validateTagName(tag string) error {
+	return localizeError(rtag.ValidateTagName(tag))
 }
 
-func writeTags(tags []string) error {
-	file, err := os.Create(rtagFile)
-	if err != nil {
-		return err
+// localizeError translates the tag name, template and tag file errors of the rtag package
+func localizeError(err error) error {
+	if errors.Is(err, rtag.ErrTagFileLocked) {
+		return fmt.Errorf(T().TagFileLocked, rtagFilePath(), rtagFilePath())
 	}
-	defer file.Close()
 
-	for _, tag := range tags {
-		if _, err := fmt.Fprintln(file, tag); err != nil {
-			return err
-		}
+	var nameErr *rtag.NameError
+	if !errors.As(err, &nameErr) {
+		return err
 	}
 
-	return nil
+	switch {
+	case errors.Is(err, rtag.ErrInvalidTagFileLine):
+		return fmt.Errorf(T().InvalidTagFileLine, rtagFilePath(), nameErr.Name)
+	case errors.Is(err, rtag.ErrInvalidTagName):
+		return fmt.Errorf(T().InvalidTagName, nameErr.Name)
+	case errors.Is(err, rtag.ErrTagExists):
+		return fmt.Errorf(T().TagAlreadyExists, nameErr.Name)
+	case errors.Is(err, rtag.ErrTagNotFound):
+		return fmt.Errorf(T().TagNotExist, nameErr.Name)
+	case (errors.Is(err, rtag.ErrMissingField) && nameErr.Field == "timestamp") || (errors.Is(err, rtag.ErrUnknownField) && nameErr.Field == "version"):
+		// Only release tag templates require {timestamp} and refuse {version}
+		return fmt.Errorf(T().NamingTemplateFields, nameErr.Name)
+	case errors.Is(err, rtag.ErrInvalidTemplate):
+		return fmt.Errorf(T().InvalidTemplate, nameErr.Name)
+	case errors.Is(err, rtag.ErrUnknownField):
+		return fmt.Errorf(T().UnknownTemplateField, nameErr.Field, nameErr.Name)
+	case errors.Is(err, rtag.ErrMissingField) && nameErr.Field == "component":
+		return fmt.Errorf(T().TemplateMissingComponent, nameErr.Name)
+	}
+	return err
 }
 
 func addTag(tag string) error {
-	tags, err := readTags()
+	project, err := openProject()
 	if err != nil {
 		return err
 	}
-
-	// 检查 tag 是否已存在
-	for _, existingTag := range tags {
-		if existingTag == tag {
-			return fmt.Errorf(T().TagAlreadyExists, tag)
-		}
-	}
-
-	tags = append(tags, tag)
-	return writeTags(tags)
+	return localizeError(project.Add(tag))
 }
 
 func removeTag(tag string) error {
-	tags, err := readTags()
+	project, err := openProject()
 	if err != nil {
 		return err
 	}
-
-	// 查找并删除 tag
-	newTags := []string{}
-	found := false
-	for _, existingTag := range tags {
-		if existingTag != tag {
-			newTags = append(newTags, existingTag)
-		} else {
-			found = true
-		}
-	}
-
-	if !found {
-		return fmt.Errorf(T().TagNotExist, tag)
-	}
-
-	return writeTags(newTags)
+	return localizeError(project.Remove(tag))
 }
 
 func interactiveAddTag() {
@@ -387,33 +709,228 @@ func interactiveAddTag() {
 }
 
 func pushTags(tags []string) {
-	currentTime := time.Now().Format("200601021504") // YYYYMMDDHHMM
+	report := releaseTags(tags, time.Now())
+	if pushReportFile != "" {
+		writeReport(pushReportFile, report)
+	}
+}
 
-	fmt.Printf(T().StartPushingTags+"\n", currentTime)
+// releaseReport is the JSON report of a release in one repository
+type releaseReport struct {
+	Timestamp string       `json:"timestamp"`
+	Tags      []string     `json:"tags"`
+	Created   []string     `json:"created,omitempty"`
+	Pushed    bool         `json:"pushed"`
+	Hooks     []hookResult `json:"hooks,omitempty"`
 
-	for _, tag := range tags {
-		gitTag := fmt.Sprintf("release-%s-%s", currentTime, tag)
-		fmt.Printf(T().CreateGitTag+"\n", gitTag)
+	Notifications  []notifyResult        `json:"notifications,omitempty"`
+	HostedReleases []hostedReleaseResult `json:"hosted_releases,omitempty"`
+}
+
+// componentRelease is the tag a release created for a component
+type componentRelease struct {
+	Component string `json:"component"`
+	Tag       string `json:"tag"`
+}
+
+// releaseRun describes a release to runRelease
+type releaseRun struct {
+	Components []string
+	Time       time.Time
+	Commit     string                        // released commit, HEAD when empty
+	TagName    func(component string) string // tag created for a component
+	// History returns the tags of each component from oldest to newest, from
+	// which hosted releases and webhooks take the previous release
+	History func() (map[string][]rtag.Release, error)
+	// Create creates the tags, adding the freeze override annotation when it
+	// is not empty, and returns the created tags and the refspecs to push. It
+	// reports its own failures and returns false to stop the release.
+	Create func(config projectConfig, annotation string) ([]string, []string, bool)
+}
+
+// runRelease is the pipeline of every command creating release tags: it
+// locks the components, checks the policy and the freezes, runs the
+// pre-release hooks, creates and pushes the tags, and then runs the
+// post-release hooks, creates the hosted releases and notifies the webhooks
+func runRelease(run releaseRun) releaseReport {
+	report := releaseReport{Timestamp: run.Time.Format(rtag.TimestampFormat), Tags: run.Components}
+	// A release that was refused or not pushed fails the command
+	defer func() {
+		if !report.Pushed {
+			exitCode = 1
+		}
+	}()
+
+	config, err := loadProjectConfig()
+	if err != nil {
+		fmt.Printf(T().LoadConfigFailed+"\n", err)
+		return report
+	}
+
+	if !checkPush() {
+		return report
+	}
+
+	commit := run.Commit
+	if commit == "" {
+		if commit, err = resolveCommit("HEAD"); err != nil {
+			fmt.Printf(T().ResolveCommitFailed+"\n", "HEAD", err)
+			return report
+		}
+	}
+
+	// 先加锁再检查策略，min_interval 才能看到上一个持锁者刚完成的发布
+	unlock, ok := lockRelease(run.Components, config)
+	if !ok {
+		return report
+	}
+	defer unlock()
 
-		// 执行 git tag 命令
-		if err := executeCommand("git", "tag", gitTag); err != nil {
-			fmt.Printf(T().CreateTagFailed+"\n", gitTag, err)
+	if !checkReleasePolicy(run.Components, commit, config.Policy, run.Time) {
+		return report
+	}
+
+	annotation, ok := checkReleaseFreezes(run.Components, config.Freezes, run.Time)
+	if !ok {
+		return report
+	}
+
+	hooks, ok := runReleaseHooks(hookPreRelease, config.Hooks.PreRelease, report.Timestamp, commit, componentReleases(run, nil))
+	report.Hooks = append(report.Hooks, hooks...)
+	if !ok {
+		fmt.Println(T().ReleaseAbortedByHook)
+		return report
+	}
+
+	created, refspecs, ok := run.Create(config, annotation)
+	report.Created = created
+	if !ok {
+		return report
+	}
+	report.Pushed = pushRefspecs(refspecs)
+	unlock()
+	if !report.Pushed {
+		return report
+	}
+
+	released := componentReleases(run, created)
+	hooks, _ = runReleaseHooks(hookPostRelease, config.Hooks.PostRelease, report.Timestamp, commit, released)
+	report.Hooks = append(report.Hooks, hooks...)
+
+	if config.HostedReleases.Provider == "" && len(config.Webhooks) == 0 {
+		return report
+	}
+	history, err := run.History()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return report
+	}
+	report.HostedReleases = createHostedReleases(config.HostedReleases, commit, released, history)
+	report.Notifications = notifyReleases(config, report.Timestamp, commit, released, history)
+	return report
+}
+
+// componentReleases returns the tag of each component of a release, only
+// those among created unless created is nil
+func componentReleases(run releaseRun, created []string) []componentRelease {
+	var releases []componentRelease
+	for _, component := range run.Components {
+		tag := run.TagName(component)
+		if created == nil || slices.Contains(created, tag) {
+			releases = append(releases, componentRelease{Component: component, Tag: tag})
+		}
+	}
+	return releases
+}
+
+// releaseTags 使用给定的时间在 HEAD 上创建并推送 tags 的发布标签
+func releaseTags(tags []string, now time.Time) releaseReport {
+	timestamp := now.Format(rtag.TimestampFormat)
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().ReleaseFailed+"\n", err)
+		exitCode = 1
+		return releaseReport{Timestamp: timestamp, Tags: tags}
+	}
+
+	return runRelease(releaseRun{
+		Components: tags,
+		Time:       now,
+		TagName: func(component string) string {
+			return project.Naming().Format(map[string]string{"timestamp": timestamp, "component": component})
+		},
+		History: listAllReleases,
+		Create: func(config projectConfig, annotation string) ([]string, []string, bool) {
+			fmt.Printf(T().StartPushingTags+"\n", timestamp)
+			created, refspecs := createReleaseTags(tags, now, config.FloatingTags || pushFloating, annotation)
+			return created, refspecs, true
+		},
+	})
+}
+
+// writeReport writes a JSON report and tells where it went
+func writeReport(path string, report any) {
+	// Hook commands and output often contain < > and &, keep them readable
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(report)
+	if err == nil {
+		err = os.WriteFile(path, data.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Printf(T().WriteReportFailed+"\n", err)
+	} else {
+		fmt.Printf(T().ReportWritten+"\n", path)
+	}
+}
+
+// createReleaseTags 在 HEAD 上为每个 tag 创建发布标签，返回成功创建的标签和需要推送的 refspecs。
+// message 不为空时创建附注标签
+func createReleaseTags(tags []string, now time.Time, floating bool, message string) ([]string, []string) {
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return nil, nil
+	}
+
+	result, err := project.Release(runContext, tags, rtag.ReleaseOptions{Time: now, Floating: floating, NoPush: true, Message: message})
+	if err != nil {
+		fmt.Printf(T().ReleaseFailed+"\n", err)
+		return nil, nil
+	}
+
+	for _, tag := range result.Tags {
+		fmt.Printf(T().CreateGitTag+"\n", tag.Tag)
+		if tag.Err != nil {
+			fmt.Printf(T().CreateTagFailed+"\n", tag.Tag, localizeError(tag.Err))
 			continue
 		}
+		recordCreatedTag(tag.Tag)
+
+		// 浮动标签已移动到新的发布
+		if tag.Floating != "" {
+			recordMovedTag(tag.Floating, tag.FloatingOld)
+			fmt.Printf(T().UpdateFloatingTag+"\n", tag.Floating, tag.Tag)
+		}
+	}
+
+	return result.Created(), result.Refspecs
+}
+
+// pushRefspecs 推送新创建的 tags 到远程仓库
+func pushRefspecs(refspecs []string) bool {
+	if len(refspecs) == 0 {
+		return false
 	}
 
-	// 推送所有 tags 到远程仓库
 	fmt.Println(T().PushingTagsToRemote)
-	if err := executeCommand("git", "push", "origin", "--tags"); err != nil {
+	if err := pushRefs(refspecs); err != nil {
 		fmt.Printf(T().PushTagsFailed+"\n", err)
-	} else {
-		fmt.Println(T().PushTagsSuccess)
+		return false
 	}
-}
 
-func executeCommand(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	fmt.Println(T().PushTagsSuccess)
+	return true
 }
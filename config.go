@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// projectConfigFile is the per-project configuration file stored next to .rtag
+const projectConfigFile = ".rtag.json"
+
+// projectConfig contains the per-project settings
+type projectConfig struct {
+	// Environments lists the promotion environments in order, e.g. staging before prod
+	Environments []string `json:"environments"`
+
+	// TagTemplate names the release tags, rtag.DefaultTemplate when empty. It
+	// must contain {component} and {timestamp}.
+	TagTemplate string `json:"tag_template"`
+
+	// FloatingTags moves a release-latest-{tag} tag to each new release
+	FloatingTags bool `json:"floating_tags"`
+
+	// PushRetries is how many times a push failing with a transient error is retried
+	PushRetries int `json:"push_retries"`
+
+	// ReleaseLock locks the released components on the remote so concurrent releases of them fail
+	ReleaseLock bool `json:"release_lock"`
+
+	// ReleaseLockTTL is how long a release lock is valid, e.g. "15m", in case the release crashes
+	ReleaseLockTTL string `json:"release_lock_ttl"`
+
+	// Policy holds the rules checked before any release tag is created
+	Policy rtag.Policy `json:"policy"`
+
+	// Freezes lists the periods during which releases are blocked unless overridden
+	Freezes []rtag.Freeze `json:"freezes"`
+
+	// Hooks lists the commands run before and after a release
+	Hooks releaseHooks `json:"hooks"`
+
+	// Webhooks are notified of each released component after a successful push
+	Webhooks []webhook `json:"webhooks"`
+
+	// WebhookRetries is how many times a failed webhook delivery is retried
+	WebhookRetries int `json:"webhook_retries"`
+
+	// HostedReleases creates a release on GitHub, GitLab or Gitea for each new release tag
+	HostedReleases hostedReleaseConfig `json:"hosted_releases"`
+}
+
+// defaultProjectConfig returns the settings used when no config file exists
+func defaultProjectConfig() projectConfig {
+	return projectConfig{
+		Environments:   []string{"staging", "prod"},
+		PushRetries:    rtag.DefaultRetryPolicy.Retries,
+		WebhookRetries: rtag.DefaultRetryPolicy.Retries,
+		ReleaseLockTTL: "15m",
+	}
+}
+
+// loadProjectConfig loads the project config, falling back to defaults for missing settings
+func loadProjectConfig() (projectConfig, error) {
+	config := defaultProjectConfig()
+
+	data, err := os.ReadFile(filepath.Join(projectRoot(), projectConfigFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return config, err
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %v", projectConfigFile, err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// dependenciesOf returns the tags a tag depends on
+func dependenciesOf(content rtag.TagFile, tag string) []string {
+	var deps []string
+	for _, dependency := range content.Dependencies {
+		if dependency.Tag == tag {
+			deps = append(deps, dependency.DependsOn...)
+		}
+	}
+	return deps
+}
+
+// withDependents adds every tag that directly or transitively depends on one of tags
+func withDependents(content rtag.TagFile, tags []string) []string {
+	result := append([]string{}, tags...)
+	for i := 0; i < len(result); i++ {
+		for _, dependency := range content.Dependencies {
+			if containsTag(dependency.DependsOn, result[i]) && !containsTag(result, dependency.Tag) && containsTag(content.Tags, dependency.Tag) {
+				result = append(result, dependency.Tag)
+			}
+		}
+	}
+	return result
+}
+
+// orderTags sorts tags so that every tag comes after the tags it depends on.
+// Dependencies outside of tags are ignored and the given order is kept where
+// the dependencies allow it. A dependency cycle is reported as an error.
+func orderTags(content rtag.TagFile, tags []string) ([]string, error) {
+	var ordered []string
+	done := make(map[string]bool)
+
+	for len(ordered) < len(tags) {
+		progressed := false
+		for _, tag := range tags {
+			if done[tag] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range dependenciesOf(content, tag) {
+				if containsTag(tags, dep) && !done[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				done[tag] = true
+				ordered = append(ordered, tag)
+				progressed = true
+			}
+		}
+
+		if !progressed {
+			return nil, fmt.Errorf(T().DependencyCycle, strings.Join(findCycle(content, tags, done), " -> "))
+		}
+	}
+
+	return ordered, nil
+}
+
+// findCycle returns a dependency cycle among the tags that are not done
+func findCycle(content rtag.TagFile, tags []string, done map[string]bool) []string {
+	var path []string
+	onPath := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var visit func(tag string) []string
+	visit = func(tag string) []string {
+		if onPath[tag] {
+			for i, t := range path {
+				if t == tag {
+					return append(append([]string{}, path[i:]...), tag)
+				}
+			}
+		}
+		if visited[tag] {
+			return nil
+		}
+
+		visited[tag] = true
+		onPath[tag] = true
+		path = append(path, tag)
+		for _, dep := range dependenciesOf(content, tag) {
+			if containsTag(tags, dep) && !done[dep] {
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		onPath[tag] = false
+		return nil
+	}
+
+	for _, tag := range tags {
+		if !done[tag] {
+			if cycle := visit(tag); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
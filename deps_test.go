@@ -0,0 +1,106 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// tagFileWith returns a tag file with tags and dependencies given as "tag: dep dep"
+func tagFileWith(tags []string, dependencies ...string) rtag.TagFile {
+	content := rtag.TagFile{Tags: tags}
+	for _, line := range dependencies {
+		tag, deps, _ := strings.Cut(line, ":")
+		content.Dependencies = append(content.Dependencies, rtag.Dependency{Tag: tag, DependsOn: strings.Fields(deps)})
+	}
+	return content
+}
+
+func TestOrderTags(t *testing.T) {
+	chain := tagFileWith([]string{"web", "api", "db"}, "web: api", "api: db")
+	diamond := tagFileWith([]string{"app", "left", "right", "base"}, "app: left right", "left: base", "right: base")
+
+	tests := []struct {
+		name    string
+		content rtag.TagFile
+		tags    []string
+		want    []string
+		cycle   string // path expected in the error
+	}{
+		{"no dependencies", tagFileWith([]string{"b", "a"}), []string{"b", "a"}, []string{"b", "a"}, ""},
+		{"chain", chain, []string{"web", "api", "db"}, []string{"db", "api", "web"}, ""},
+		{"chain with a missing link", chain, []string{"web", "db"}, []string{"web", "db"}, ""},
+		{"diamond", diamond, []string{"app", "left", "right", "base"}, []string{"base", "left", "right", "app"}, ""},
+		{"diamond keeps the given order", diamond, []string{"right", "left", "app", "base"}, []string{"base", "right", "left", "app"}, ""},
+		{"dependency outside the tags", tagFileWith([]string{"a", "b"}, "a: external"), []string{"a", "b"}, []string{"a", "b"}, ""},
+		{"self-dependency", tagFileWith([]string{"a", "b"}, "a: a"), []string{"b", "a"}, nil, "a -> a"},
+		{"cycle", tagFileWith([]string{"a", "b", "c", "d"}, "a: b", "b: c", "c: a", "d: a"), []string{"d", "a", "b", "c"}, nil, "a -> b -> c -> a"},
+		{"cycle after ordered tags", tagFileWith([]string{"base", "x", "y"}, "x: base y", "y: x"), []string{"x", "y", "base"}, nil, "x -> y -> x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderTags(tt.content, tt.tags)
+			if tt.cycle != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), ": "+tt.cycle) {
+					t.Errorf("orderTags() error = %v, want the cycle %s", err, tt.cycle)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	content := tagFileWith([]string{"a", "b", "c", "d"}, "a: b", "b: c d", "d: b")
+
+	tests := []struct {
+		name string
+		done map[string]bool
+		want []string
+	}{
+		{"cycle reached from a", map[string]bool{}, []string{"b", "d", "b"}},
+		{"no cycle among undone tags", map[string]bool{"d": true}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findCycle(content, content.Tags, tt.done); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDependents(t *testing.T) {
+	content := tagFileWith([]string{"app", "left", "right", "base", "tool"},
+		"app: left right", "left: base", "right: base", "tool: base", "removed: base")
+
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"leaf", []string{"app"}, []string{"app"}},
+		{"chain", []string{"left"}, []string{"left", "app"}},
+		{"diamond counted once", []string{"base"}, []string{"base", "left", "right", "tool", "app"}},
+		{"already selected", []string{"right", "app"}, []string{"right", "app"}},
+		{"none", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withDependents(content, tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withDependents(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
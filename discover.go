@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// componentCandidate is a tag proposed by repository discovery
+type componentCandidate struct {
+	Tag    string
+	Source string
+}
+
+// discoverComponents proposes tags from cmd/* directories, nested Go modules,
+// Dockerfiles and existing release tags, keeping the first source of each tag
+func discoverComponents(root string) ([]componentCandidate, error) {
+	var candidates []componentCandidate
+	seen := make(map[string]bool)
+	propose := func(tag, source string) {
+		if tag == "" || seen[tag] || validateTagName(tag) != nil {
+			return
+		}
+		seen[tag] = true
+		candidates = append(candidates, componentCandidate{Tag: tag, Source: source})
+	}
+
+	// cmd/* 目录
+	entries, err := os.ReadDir(filepath.Join(root, "cmd"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			propose(entry.Name(), "cmd/"+entry.Name())
+		}
+	}
+
+	// 嵌套的 Go 模块
+	modules, err := discoverGoModules(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range modules {
+		propose(module.Dir, module.Dir+"/go.mod")
+	}
+
+	// Dockerfiles
+	dockerfiles, err := findDockerfiles(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, dockerfile := range dockerfiles {
+		propose(dockerfileComponent(root, dockerfile), dockerfile)
+	}
+
+	// 已有的发布标签，不在 git 仓库中时忽略
+	if project, err := openProject(); err == nil {
+		if components, err := listReleaseComponents(); err == nil {
+			for _, component := range components {
+				propose(component, project.Naming().Format(map[string]string{"timestamp": "*", "component": component}))
+			}
+		}
+	}
+
+	return candidates, nil
+}
+
+// findDockerfiles returns the slash separated paths of Dockerfile, Dockerfile.{name}
+// and {name}.Dockerfile files below root
+func findDockerfiles(root string) ([]string, error) {
+	var dockerfiles []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if name == "Dockerfile" || strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile") {
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			dockerfiles = append(dockerfiles, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+
+	return dockerfiles, err
+}
+
+// dockerfileComponent derives a tag from a Dockerfile path: the suffix of
+// Dockerfile.{name}, the prefix of {name}.Dockerfile, or otherwise the name of
+// the directory containing the Dockerfile
+func dockerfileComponent(root, dockerfile string) string {
+	name := filepath.Base(dockerfile)
+	if suffix, found := strings.CutPrefix(name, "Dockerfile."); found {
+		return suffix
+	}
+	if prefix, found := strings.CutSuffix(name, ".Dockerfile"); found {
+		return prefix
+	}
+
+	dir := filepath.Dir(filepath.FromSlash(dockerfile))
+	if dir == "." {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return ""
+		}
+		return filepath.Base(abs)
+	}
+	return filepath.Base(dir)
+}
+
+// interactiveDiscoverTags asks the user to confirm each discovered tag that is not in .rtag yet
+func interactiveDiscoverTags() {
+	candidates, err := discoverComponents(projectRoot())
+	if err != nil {
+		fmt.Printf(T().DiscoverComponentsFailed+"\n", err)
+		return
+	}
+
+	tags, err := readTags()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	var proposals []componentCandidate
+	for _, candidate := range candidates {
+		if !containsTag(tags, candidate.Tag) {
+			proposals = append(proposals, candidate)
+		}
+	}
+
+	if len(proposals) == 0 {
+		fmt.Println(T().NoComponentsDiscovered)
+		return
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, candidate := range proposals {
+		fmt.Printf(T().ConfirmDiscoveredTag, candidate.Tag, candidate.Source)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Printf(T().ReadInputFailed+"\n", err)
+			return
+		}
+
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "y" && input != "yes" {
+			continue
+		}
+
+		if err := addTag(candidate.Tag); err != nil {
+			fmt.Printf(T().AddTagFailed+"\n", err)
+		} else {
+			fmt.Printf(T().AddTagSuccess+"\n", candidate.Tag)
+		}
+	}
+}
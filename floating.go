@@ -0,0 +1,25 @@
+package main
+
+import "fmt"
+
+// updateFloatingTag force-moves the floating tag of a component to a release
+// tag and returns the force refspec needed to push it, or false if it failed
+func updateFloatingTag(component, gitTag string) (string, bool) {
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return "", false
+	}
+
+	floatingTag := project.FloatingTagName(component)
+	fmt.Printf(T().UpdateFloatingTag+"\n", floatingTag, gitTag)
+
+	old, _ := gitRepo().ResolveRef(runContext, "refs/tags/"+floatingTag)
+	refspec, err := project.MoveFloatingTag(runContext, component, gitTag)
+	if err != nil {
+		fmt.Printf(T().UpdateFloatingTagFailed+"\n", floatingTag, err)
+		return "", false
+	}
+	recordMovedTag(floatingTag, old)
+	return refspec, true
+}
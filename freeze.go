@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// checkReleaseFreezes stops the release of tags during a freeze unless it is
+// overridden with a reason. It returns the annotation recording the override
+// on the release tags, and false if the release must not go ahead.
+func checkReleaseFreezes(tags []string, freezes []rtag.Freeze, now time.Time) (string, bool) {
+	active, err := rtag.ActiveFreezes(freezes, tags, now)
+	if err != nil {
+		fmt.Printf(T().FreezeCheckFailed+"\n", localizePolicyError(err))
+		return "", false
+	}
+
+	if len(active) == 0 {
+		return "", true
+	}
+
+	if !overrideFreeze {
+		fmt.Println(T().ReleaseFrozen)
+		for _, window := range active {
+			fmt.Println(describeFreezeWindow(window, now))
+		}
+		fmt.Println(T().FreezeOverrideHint)
+		return "", false
+	}
+
+	reason := strings.TrimSpace(freezeReason)
+	if reason == "" {
+		fmt.Println(T().FreezeReasonRequired)
+		return "", false
+	}
+
+	labels := make([]string, len(active))
+	for i, window := range active {
+		labels[i] = window.Freeze.Label()
+	}
+	fmt.Printf(T().FreezeOverridden+"\n", strings.Join(labels, ", "), reason)
+	return freezeOverrideAnnotation(labels, reason), true
+}
+
+// freezeOverrideAnnotation builds the annotation of release tags created
+// during a freeze: the overridden freezes on the subject line and the reason
+// as a trailer
+func freezeOverrideAnnotation(labels []string, reason string) string {
+	return fmt.Sprintf("Release during freeze %s\n\nFreeze-Override-Reason: %s\n", strings.Join(labels, ", "), reason)
+}
+
+// withFreezeOverride appends the freeze override annotation, if any, to the
+// annotation of a rollback or promotion tag
+func withFreezeOverride(message, override string) string {
+	if override == "" {
+		return message
+	}
+	return message + "\n\n" + override
+}
+
+// describeFreezeWindow formats a freeze window and the components it blocks
+func describeFreezeWindow(window rtag.FreezeWindow, now time.Time) string {
+	components := T().FreezeAllComponents
+	if len(window.Freeze.Components) > 0 {
+		components = strings.Join(window.Freeze.Components, ", ")
+	}
+
+	line := fmt.Sprintf(T().FreezeWindowLine, window.Freeze.Label(),
+		window.Start.Format("2006-01-02 15:04 MST"), window.End.Format("2006-01-02 15:04 MST"), components)
+	if window.Active(now) {
+		line += " " + T().FreezeActive
+	}
+	return line
+}
+
+func runFreezeList(cmd *cobra.Command, args []string) {
+	config, err := loadProjectConfig()
+	if err != nil {
+		fmt.Printf(T().LoadConfigFailed+"\n", err)
+		return
+	}
+
+	now := time.Now()
+	windows, err := rtag.FreezeWindows(config.Freezes, now, time.Duration(freezeDays)*24*time.Hour)
+	if err != nil {
+		fmt.Printf(T().FreezeCheckFailed+"\n", localizePolicyError(err))
+		return
+	}
+
+	if len(windows) == 0 {
+		fmt.Printf(T().NoFreezes+"\n", freezeDays)
+		return
+	}
+
+	fmt.Printf(T().FreezeListHeader+"\n", freezeDays)
+	for _, window := range windows {
+		fmt.Println(describeFreezeWindow(window, now))
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// prefixedTemplate returns the template of the {prefix}-{timestamp}-{component}
+// tags created by promote for an environment and by train
+func prefixedTemplate(prefix string) (rtag.Template, error) {
+	template, err := rtag.ParseTemplate(prefix + "-{timestamp}-{component}")
+	if err != nil {
+		return rtag.Template{}, localizeError(err)
+	}
+	return template, nil
+}
+
+// resolveCommit returns the commit hash a ref points to
+func resolveCommit(ref string) (string, error) {
+	return gitRepo().ResolveRef(runContext, ref)
+}
+
+// yankedFetched records the repositories whose yanks were fetched in this run
+var yankedFetched = make(map[string]bool)
+
+// fetchYanked brings in the releases yanked from other clones, once per
+// repository and run. Reading commands also work offline, so a remote that
+// cannot be reached leaves the local yanks as they are.
+func fetchYanked() {
+	root := repoRoot()
+	if yankedFetched[root] {
+		return
+	}
+	yankedFetched[root] = true
+	rtag.FetchYanked(runContext, gitRepo(), "origin")
+}
+
+// listReleases returns the releases of a component ordered from oldest to newest
+func listReleases(component string) ([]rtag.Release, error) {
+	fetchYanked()
+	project, err := openProject()
+	if err != nil {
+		return nil, err
+	}
+	return project.History(runContext, component)
+}
+
+// listAllReleases returns the releases of every component from a single tag listing
+func listAllReleases() (map[string][]rtag.Release, error) {
+	fetchYanked()
+	project, err := openProject()
+	if err != nil {
+		return nil, err
+	}
+	return project.Releases(runContext)
+}
+
+// listTagRefs returns all tags of the repository ordered by creation date
+func listTagRefs() ([]rtag.TagRef, error) {
+	return gitRepo().ListTags(runContext)
+}
+
+// listReleaseComponents returns the components that have release tags, in tag name order
+func listReleaseComponents() ([]string, error) {
+	project, err := openProject()
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := listTagRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].Name < refs[j].Name
+	})
+
+	var components []string
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if values, ok := project.Naming().Match(ref.Name); ok && !seen[values["component"]] {
+			seen[values["component"]] = true
+			components = append(components, values["component"])
+		}
+	}
+	return components, nil
+}
+
+// listPrefixedTags returns the {prefix}-{timestamp}-{component} tags of a component ordered from oldest to newest
+func listPrefixedTags(prefix, component string) ([]rtag.Release, error) {
+	template, err := prefixedTemplate(prefix)
+	if err != nil {
+		return nil, err
+	}
+	fetchYanked()
+	return rtag.ListReleases(runContext, gitRepo(), template, component)
+}
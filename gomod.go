@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// goModule describes a nested Go module of the repository
+type goModule struct {
+	Dir        string // slash separated directory relative to the repository root
+	ModulePath string
+	Major      int // major version required by the module path suffix, 0 when there is none
+}
+
+// goVersion is a parsed vMAJOR.MINOR.PATCH[-prerelease] semantic version
+type goVersion struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+func (v goVersion) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// less reports whether v sorts before other by semantic version precedence
+func (v goVersion) less(other goVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	if v.Patch != other.Patch {
+		return v.Patch < other.Patch
+	}
+	if v.Prerelease == "" || other.Prerelease == "" {
+		return v.Prerelease != "" && other.Prerelease == ""
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease) < 0
+}
+
+// comparePrerelease compares prereleases identifier by identifier: numeric
+// identifiers compare as numbers and sort before alphanumeric ones, so rc.10
+// comes after rc.9, and a prefix of identifiers sorts first
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return cmp.Compare(an, bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+// parseGoVersion parses a vMAJOR.MINOR.PATCH[-prerelease] version
+func parseGoVersion(s string) (goVersion, bool) {
+	rest, found := strings.CutPrefix(s, "v")
+	if !found {
+		return goVersion{}, false
+	}
+
+	rest, prerelease, _ := strings.Cut(rest, "-")
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return goVersion{}, false
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return goVersion{}, false
+		}
+		numbers[i] = n
+	}
+
+	return goVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Prerelease: prerelease}, true
+}
+
+// tagPrefix returns the directory prefix of the module's tags. For modules in
+// a major version subdirectory such as libs/auth/v2 the /v2 element is part of
+// the version, so the prefix is libs/auth.
+func (m goModule) tagPrefix() string {
+	dir := m.Dir
+	if m.Major >= 2 && path.Base(dir) == fmt.Sprintf("v%d", m.Major) {
+		dir = path.Dir(dir)
+	}
+	if dir == "." {
+		return ""
+	}
+	return dir + "/"
+}
+
+// tagName returns the git tag the Go toolchain expects for a module version
+func (m goModule) tagName(version goVersion) string {
+	return m.tagPrefix() + version.String()
+}
+
+// checkVersion verifies that a version matches the module path's major version suffix
+func (m goModule) checkVersion(version goVersion) error {
+	if m.Major >= 2 && version.Major != m.Major {
+		return fmt.Errorf(T().GoModuleMajorMismatch, version, m.ModulePath, m.Major)
+	}
+	if m.Major < 2 && version.Major >= 2 {
+		return fmt.Errorf(T().GoModuleMajorSuffixMissing, version, m.ModulePath, version.Major)
+	}
+	return nil
+}
+
+func runGoModDiscover(cmd *cobra.Command, args []string) {
+	modules, err := discoverGoModules(projectRoot())
+	if err != nil {
+		fmt.Printf(T().DiscoverGoModulesFailed+"\n", err)
+		return
+	}
+
+	if len(modules) == 0 {
+		fmt.Println(T().NoGoModulesFound)
+		return
+	}
+
+	tags, err := readTags()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	for _, module := range modules {
+		if containsTag(tags, module.Dir) {
+			fmt.Printf(T().GoModuleAlreadyRegistered+"\n", module.Dir, module.ModulePath)
+			continue
+		}
+
+		if err := addTag(module.Dir); err != nil {
+			fmt.Printf(T().AddTagFailed+"\n", err)
+		} else {
+			fmt.Printf(T().GoModuleRegistered+"\n", module.Dir, module.ModulePath)
+		}
+	}
+}
+
+func runGoModTag(cmd *cobra.Command, args []string) {
+	dir := path.Clean(filepath.ToSlash(args[0]))
+
+	tags, err := readTags()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	if !containsTag(tags, dir) {
+		fmt.Printf(T().TagNotExistInFile+"\n", dir)
+		return
+	}
+
+	module, err := loadGoModule(projectRoot(), dir)
+	if err != nil {
+		fmt.Printf(T().GoModuleTagFailed+"\n", err)
+		return
+	}
+
+	var version goVersion
+	if len(args) > 1 {
+		var ok bool
+		if version, ok = parseGoVersion(args[1]); !ok {
+			fmt.Printf(T().GoModuleTagFailed+"\n", fmt.Errorf(T().InvalidGoVersion, args[1]))
+			return
+		}
+	} else {
+		if version, err = nextGoVersion(module, goModBump); err != nil {
+			fmt.Printf(T().GoModuleTagFailed+"\n", err)
+			return
+		}
+	}
+
+	if err := module.checkVersion(version); err != nil {
+		fmt.Printf(T().GoModuleTagFailed+"\n", err)
+		return
+	}
+
+	gitTag := module.tagName(version)
+	runRelease(releaseRun{
+		Components: []string{dir},
+		Time:       time.Now(),
+		TagName: func(component string) string {
+			return gitTag
+		},
+		History: func() (map[string][]rtag.Release, error) {
+			history, err := goModuleHistory(module)
+			return map[string][]rtag.Release{dir: history}, err
+		},
+		Create: func(config projectConfig, override string) ([]string, []string, bool) {
+			fmt.Printf(T().CreateGitTag+"\n", gitTag)
+			if err := createTag(gitTag, "HEAD", rtag.TagOptions{Message: override}); err != nil {
+				fmt.Printf(T().CreateTagFailed+"\n", gitTag, err)
+				return nil, nil, false
+			}
+			return []string{gitTag}, []string{rtag.TagRefspec(gitTag)}, true
+		},
+	})
+}
+
+// discoverGoModules finds the nested go.mod files below root, skipping the
+// root module itself and the directories the Go toolchain ignores
+func discoverGoModules(root string) ([]goModule, error) {
+	var modules []goModule
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Name() != "go.mod" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil || rel == "." {
+			return err
+		}
+
+		module, err := loadGoModule(root, filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		modules = append(modules, module)
+		return nil
+	})
+
+	return modules, err
+}
+
+// loadGoModule reads the module path of the go.mod in dir below root
+func loadGoModule(root, dir string) (goModule, error) {
+	file, err := os.Open(filepath.Join(root, filepath.FromSlash(dir), "go.mod"))
+	if err != nil {
+		return goModule{}, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, found := strings.CutPrefix(line, "module"); found && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			rest, _, _ = strings.Cut(rest, "//")
+			modulePath := strings.Trim(strings.TrimSpace(rest), "\"`")
+			return goModule{Dir: dir, ModulePath: modulePath, Major: modulePathMajor(modulePath)}, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return goModule{}, err
+	}
+	return goModule{}, fmt.Errorf(T().GoModuleNoModuleLine, path.Join(dir, "go.mod"))
+}
+
+// modulePathMajor returns N for module paths ending in /vN with N >= 2, otherwise 0
+func modulePathMajor(modulePath string) int {
+	last := path.Base(modulePath)
+	if len(last) < 2 || last[0] != 'v' {
+		return 0
+	}
+	n, err := strconv.Atoi(last[1:])
+	if err != nil || n < 2 || last[1] == '0' {
+		return 0
+	}
+	return n
+}
+
+// listGoVersions returns the released versions of a module from oldest to newest
+func listGoVersions(module goModule) ([]goVersion, error) {
+	refs, err := listTagRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []goVersion
+	for _, ref := range refs {
+		rest, found := strings.CutPrefix(ref.Name, module.tagPrefix())
+		if !found || strings.Contains(rest, "/") {
+			continue
+		}
+		if version, ok := parseGoVersion(rest); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].less(versions[j])
+	})
+	return versions, nil
+}
+
+// goModuleHistory returns the version tags of a module as releases, from the
+// lowest version to the highest
+func goModuleHistory(module goModule) ([]rtag.Release, error) {
+	refs, err := listTagRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []rtag.Release
+	versions := make(map[string]goVersion)
+	for _, ref := range refs {
+		rest, found := strings.CutPrefix(ref.Name, module.tagPrefix())
+		if !found || strings.Contains(rest, "/") {
+			continue
+		}
+		if version, ok := parseGoVersion(rest); ok {
+			versions[ref.Name] = version
+			releases = append(releases, rtag.Release{Tag: ref.Name, Component: module.Dir, Commit: ref.Commit})
+		}
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return versions[releases[i].Tag].less(versions[releases[j].Tag])
+	})
+	return releases, nil
+}
+
+// nextGoVersion computes the next version of a module by bumping its latest
+// release. A module without releases starts at v{major}.0.0 for /vN modules
+// and at v0.1.0 otherwise.
+func nextGoVersion(module goModule, bump string) (goVersion, error) {
+	if bump != "major" && bump != "minor" && bump != "patch" {
+		return goVersion{}, fmt.Errorf(T().InvalidBump, bump)
+	}
+
+	versions, err := listGoVersions(module)
+	if err != nil {
+		return goVersion{}, err
+	}
+
+	var latest *goVersion
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Prerelease == "" && (module.Major < 2 || versions[i].Major == module.Major) {
+			latest = &versions[i]
+			break
+		}
+	}
+
+	if latest == nil {
+		if module.Major >= 2 {
+			return goVersion{Major: module.Major}, nil
+		}
+		return goVersion{Minor: 1}, nil
+	}
+
+	next := *latest
+	switch bump {
+	case "major":
+		next = goVersion{Major: latest.Major + 1}
+	case "minor":
+		next = goVersion{Major: latest.Major, Minor: latest.Minor + 1}
+	case "patch":
+		next = goVersion{Major: latest.Major, Minor: latest.Minor, Patch: latest.Patch + 1}
+	}
+
+	return next, nil
+}
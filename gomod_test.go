@@ -0,0 +1,180 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want goVersion
+		ok   bool
+	}{
+		{"v1.2.3", goVersion{Major: 1, Minor: 2, Patch: 3}, true},
+		{"v0.0.0", goVersion{}, true},
+		{"v2.0.0-rc.1", goVersion{Major: 2, Prerelease: "rc.1"}, true},
+		{"v1.0.0-beta-2", goVersion{Major: 1, Prerelease: "beta-2"}, true},
+		{"v10.20.30", goVersion{Major: 10, Minor: 20, Patch: 30}, true},
+		{"1.2.3", goVersion{}, false},
+		{"v1.2", goVersion{}, false},
+		{"v1.2.3.4", goVersion{}, false},
+		{"v01.2.3", goVersion{}, false},
+		{"v1.x.3", goVersion{}, false},
+		{"v1.-2.3", goVersion{}, false},
+		{"", goVersion{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseGoVersion(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseGoVersion(%q) = %+v, %v, want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+		if ok && got.String() != tt.in {
+			t.Errorf("parseGoVersion(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestGoVersionLess(t *testing.T) {
+	// Ordered by semantic version precedence
+	ordered := []string{
+		"v0.9.0",
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0-rc.9",
+		"v1.0.0-rc.10",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.2.0",
+		"v1.10.0",
+		"v2.0.0",
+	}
+
+	versions := make([]goVersion, len(ordered))
+	for i, s := range ordered {
+		versions[len(ordered)-1-i], _ = parseGoVersion(s)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].less(versions[j])
+	})
+
+	for i, version := range versions {
+		if version.String() != ordered[i] {
+			t.Errorf("sorted versions = %v, want %v", versions, ordered)
+			break
+		}
+	}
+	for i := 1; i < len(versions); i++ {
+		if versions[i].less(versions[i-1]) || !versions[i-1].less(versions[i]) {
+			t.Errorf("%s.less(%s) is inconsistent", versions[i-1], versions[i])
+		}
+	}
+	if versions[0].less(versions[0]) {
+		t.Errorf("%s.less(%[1]s) = true", versions[0])
+	}
+}
+
+func TestGoModuleTagPrefix(t *testing.T) {
+	tests := []struct {
+		module goModule
+		want   string
+	}{
+		{goModule{Dir: ".", ModulePath: "example.com/repo"}, ""},
+		{goModule{Dir: "libs/auth", ModulePath: "example.com/repo/libs/auth"}, "libs/auth/"},
+		{goModule{Dir: "libs/auth/v2", ModulePath: "example.com/repo/libs/auth/v2", Major: 2}, "libs/auth/"},
+		{goModule{Dir: "v3", ModulePath: "example.com/repo/v3", Major: 3}, ""},
+		// A /vN path suffix without the subdirectory keeps the directory
+		{goModule{Dir: "libs/auth", ModulePath: "example.com/repo/libs/auth/v2", Major: 2}, "libs/auth/"},
+		{goModule{Dir: "libs/v2", ModulePath: "example.com/repo/libs/v3", Major: 3}, "libs/v2/"},
+		{goModule{Dir: "tools/v1", ModulePath: "example.com/repo/tools/v1"}, "tools/v1/"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.module.tagPrefix(); got != tt.want {
+			t.Errorf("%+v.tagPrefix() = %q, want %q", tt.module, got, tt.want)
+		}
+	}
+}
+
+func TestGoModuleCheckVersion(t *testing.T) {
+	v1 := goModule{Dir: "libs/auth", ModulePath: "example.com/repo/libs/auth"}
+	v2 := goModule{Dir: "libs/auth/v2", ModulePath: "example.com/repo/libs/auth/v2", Major: 2}
+
+	tests := []struct {
+		module  goModule
+		version string
+		wantErr string
+	}{
+		{v1, "v0.3.0", ""},
+		{v1, "v1.4.2", ""},
+		{v1, "v2.0.0", "/v2"},
+		{v2, "v2.0.0", ""},
+		{v2, "v2.1.0-rc.1", ""},
+		{v2, "v1.9.0", "example.com/repo/libs/auth/v2"},
+		{v2, "v3.0.0", "example.com/repo/libs/auth/v2"},
+	}
+
+	for _, tt := range tests {
+		version, _ := parseGoVersion(tt.version)
+		err := tt.module.checkVersion(version)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("checkVersion(%s) of %s = %v", tt.version, tt.module.ModulePath, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("checkVersion(%s) of %s = %v, want an error mentioning %s", tt.version, tt.module.ModulePath, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNextGoVersion(t *testing.T) {
+	root := goModule{Dir: ".", ModulePath: "example.com/repo"}
+	auth := goModule{Dir: "libs/auth", ModulePath: "example.com/repo/libs/auth"}
+	authV2 := goModule{Dir: "libs/auth/v2", ModulePath: "example.com/repo/libs/auth/v2", Major: 2}
+
+	tests := []struct {
+		name   string
+		module goModule
+		tags   []string
+		bump   string
+		want   string
+	}{
+		{"first version", auth, nil, "patch", "v0.1.0"},
+		{"patch", auth, []string{"libs/auth/v0.1.0", "libs/auth/v0.1.1"}, "patch", "v0.1.2"},
+		{"minor", auth, []string{"libs/auth/v1.2.3"}, "minor", "v1.3.0"},
+		{"major", auth, []string{"libs/auth/v1.2.3"}, "major", "v2.0.0"},
+		{"highest version, not the newest tag", auth, []string{"libs/auth/v1.4.0", "libs/auth/v1.3.9"}, "patch", "v1.4.1"},
+		{"skips prereleases", auth, []string{"libs/auth/v1.2.0", "libs/auth/v1.3.0-rc.1", "libs/auth/v1.3.0-rc.2"}, "patch", "v1.2.1"},
+		{"only prereleases", auth, []string{"libs/auth/v1.0.0-rc.1"}, "minor", "v0.1.0"},
+		{"ignores other modules", auth, []string{"v1.5.0", "libs/auth-client/v3.0.0", "libs/auth/sub/v2.0.0"}, "minor", "v0.1.0"},
+		{"root module ignores nested ones", root, []string{"libs/auth/v1.0.0", "v0.4.0"}, "minor", "v0.5.0"},
+		{"first version of a /vN module", authV2, []string{"libs/auth/v1.2.0"}, "patch", "v2.0.0"},
+		{"first /vN version after prereleases", authV2, []string{"libs/auth/v1.2.0", "libs/auth/v2.0.0-rc.1"}, "minor", "v2.0.0"},
+		{"bump of a /vN module", authV2, []string{"libs/auth/v1.9.0", "libs/auth/v2.0.0", "libs/auth/v2.1.0"}, "patch", "v2.1.1"},
+		{"newer major tags left to a /v3 module", authV2, []string{"libs/auth/v2.0.0", "libs/auth/v3.0.0"}, "minor", "v2.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useMemoryBackend(t, tt.tags...)
+			got, err := nextGoVersion(tt.module, tt.bump)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("nextGoVersion(%s, %s) = %s, want %s", tt.module.Dir, tt.bump, got, tt.want)
+			}
+		})
+	}
+
+	useMemoryBackend(t)
+	if _, err := nextGoVersion(auth, "build"); err == nil {
+		t.Error("nextGoVersion() accepted the bump build")
+	}
+}
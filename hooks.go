@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// Hook phases
+const (
+	hookPreRelease  = "pre_release"
+	hookPostRelease = "post_release"
+)
+
+// releaseHooks lists the commands run around a release
+type releaseHooks struct {
+	// PreRelease runs before any tag is created, a failing hook aborts the release
+	PreRelease []releaseHook `json:"pre_release"`
+
+	// PostRelease runs after the tags were pushed, failures are only reported
+	PostRelease []releaseHook `json:"post_release"`
+}
+
+// releaseHook is a shell command run from the project root. A hook with
+// components runs once for each released component matching them, a hook
+// without runs once for the whole release.
+type releaseHook struct {
+	Run        string   `json:"run"`
+	Components []string `json:"components"`
+	Timeout    string   `json:"timeout"` // e.g. "10m", no limit by default
+}
+
+// hookPayload is the JSON written to the standard input of a hook
+type hookPayload struct {
+	Phase     string             `json:"phase"`
+	Timestamp string             `json:"timestamp"`
+	Commit    string             `json:"commit"`
+	Component string             `json:"component,omitempty"` // set for per component hooks
+	Tag       string             `json:"tag,omitempty"`
+	Releases  []componentRelease `json:"releases"`
+}
+
+// hookResult is the outcome of one hook run, as written to release reports
+type hookResult struct {
+	Phase     string `json:"phase"`
+	Command   string `json:"command"`
+	Component string `json:"component,omitempty"`
+	ExitCode  int    `json:"exit_code"`
+	Duration  string `json:"duration"`
+	Output    string `json:"output"`
+	Error     string `json:"error,omitempty"`
+}
+
+// runReleaseHooks runs the hooks of a phase for the release of commit at
+// timestamp. Pre-release hooks stop at the first failure. It returns the
+// results and false if a hook failed.
+func runReleaseHooks(phase string, hooks []releaseHook, timestamp, commit string, releases []componentRelease) ([]hookResult, bool) {
+	if len(hooks) == 0 {
+		return nil, true
+	}
+
+	payload := hookPayload{Phase: phase, Timestamp: timestamp, Commit: commit, Releases: releases}
+
+	var results []hookResult
+	ok := true
+	for _, hook := range hooks {
+		if len(hook.Components) == 0 {
+			result := runHook(hook, payload)
+			results = append(results, result)
+			ok = ok && result.Error == ""
+		} else {
+			for _, release := range payload.Releases {
+				if !rtag.MatchAny(hook.Components, release.Component) {
+					continue
+				}
+				component := payload
+				component.Component = release.Component
+				component.Tag = release.Tag
+				result := runHook(hook, component)
+				results = append(results, result)
+				ok = ok && result.Error == ""
+				if !ok && phase == hookPreRelease {
+					break
+				}
+			}
+		}
+
+		if !ok && phase == hookPreRelease {
+			break
+		}
+	}
+	return results, ok
+}
+
+// runHook runs one hook with the release details in its environment and on
+// its standard input. The output is shown as it comes and captured.
+func runHook(hook releaseHook, payload hookPayload) hookResult {
+	result := hookResult{Phase: payload.Phase, Command: hook.Run, Component: payload.Component}
+	if payload.Component != "" {
+		fmt.Printf(T().RunningComponentHook+"\n", payload.Phase, hook.Run, payload.Component)
+	} else {
+		fmt.Printf(T().RunningHook+"\n", payload.Phase, hook.Run)
+	}
+
+	ctx := runContext
+	if hook.Timeout != "" {
+		timeout, err := time.ParseDuration(hook.Timeout)
+		if err != nil || timeout <= 0 {
+			result.Error = fmt.Sprintf(T().InvalidHookTimeout, hook.Timeout)
+			fmt.Printf(T().HookFailed+"\n", hook.Run, result.Error)
+			return result
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	input, err := json.Marshal(payload)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	var output bytes.Buffer
+	writer := io.MultiWriter(os.Stdout, &output)
+
+	cmd := shellCommand(ctx, hook.Run)
+	cmd.Dir = projectRoot()
+	cmd.Env = append(os.Environ(), hookEnv(payload)...)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	// Processes started by the hook may keep the output open after it is killed
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err = cmd.Run()
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	result.Output = output.String()
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		result.Error = err.Error()
+		fmt.Printf(T().HookFailed+"\n", hook.Run, err)
+	}
+	return result
+}
+
+// hookEnv returns the RTAG_* variables describing the release to a hook
+func hookEnv(payload hookPayload) []string {
+	components := make([]string, len(payload.Releases))
+	tags := make([]string, len(payload.Releases))
+	for i, release := range payload.Releases {
+		components[i] = release.Component
+		tags[i] = release.Tag
+	}
+
+	return []string{
+		"RTAG_PHASE=" + payload.Phase,
+		"RTAG_TIMESTAMP=" + payload.Timestamp,
+		"RTAG_COMMIT=" + payload.Commit,
+		"RTAG_COMPONENTS=" + strings.Join(components, " "),
+		"RTAG_TAGS=" + strings.Join(tags, " "),
+		"RTAG_COMPONENT=" + payload.Component,
+		"RTAG_TAG=" + payload.Tag,
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// hostedReleaseTimeout bounds one API request
+const hostedReleaseTimeout = 15 * time.Second
+
+// hostedChangelogLimit is the number of commits listed in a release body
+const hostedChangelogLimit = 200
+
+// hostedReleaseConfig creates a release object on the code hosting service for each new release tag
+type hostedReleaseConfig struct {
+	Provider   string `json:"provider"`   // "github", "gitlab" or "gitea", disabled when empty
+	APIURL     string `json:"api_url"`    // API base URL, e.g. a self-hosted server or a local stand-in
+	Repository string `json:"repository"` // "owner/repo" or GitLab project path, taken from origin by default
+	TokenEnv   string `json:"token_env"`  // environment variable holding the API token
+}
+
+// hostedProvider describes the release API of a hosting service
+type hostedProvider struct {
+	Name     string
+	APIURL   string // default API base URL, "" when it is taken from the origin host
+	APIPath  string // API path on the origin host, for self-hosted services
+	TokenEnv string
+}
+
+var hostedProviders = map[string]hostedProvider{
+	"github": {Name: "GitHub", APIURL: "https://api.github.com", TokenEnv: "GITHUB_TOKEN"},
+	"gitlab": {Name: "GitLab", APIURL: "https://gitlab.com/api/v4", TokenEnv: "GITLAB_TOKEN"},
+	"gitea":  {Name: "Gitea", APIPath: "/api/v1", TokenEnv: "GITEA_TOKEN"},
+}
+
+// hostedReleaseResult is the outcome of creating one hosted release, as written to release reports
+type hostedReleaseResult struct {
+	Tag    string `json:"tag"`
+	URL    string `json:"url,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// createHostedReleases creates a hosted release for each tag released at
+// commit, with the changelog since the previous tag in history as body.
+// Failures are reported and do not affect the release, an unreachable API
+// skips the remaining tags.
+func createHostedReleases(config hostedReleaseConfig, commit string, releases []componentRelease, history map[string][]rtag.Release) []hostedReleaseResult {
+	if config.Provider == "" || len(releases) == 0 {
+		return nil
+	}
+
+	provider, ok := hostedProviders[strings.ToLower(config.Provider)]
+	if !ok {
+		fmt.Printf(T().HostedReleasesFailed+"\n", fmt.Errorf(T().UnknownHostedProvider, config.Provider))
+		return nil
+	}
+
+	tokenEnv := config.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = provider.TokenEnv
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		fmt.Printf(T().HostedReleaseTokenMissing+"\n", tokenEnv, provider.Name)
+		return nil
+	}
+
+	apiURL, repository, err := hostedTarget(config, provider)
+	if err != nil {
+		fmt.Printf(T().HostedReleasesFailed+"\n", err)
+		return nil
+	}
+
+	var results []hostedReleaseResult
+	for i, release := range releases {
+		tag := release.Tag
+		previous, commits := releaseCommits(history[release.Component], tag, commit)
+
+		result := hostedReleaseResult{Tag: tag}
+		result.URL, result.Status, err = postHostedRelease(strings.ToLower(config.Provider), apiURL, repository, token, tag, releaseNotes(previous, commits))
+		if err == nil {
+			fmt.Printf(T().HostedReleaseCreated+"\n", provider.Name, tag, result.URL)
+			results = append(results, result)
+			continue
+		}
+
+		// The release exists, only its URL is unknown
+		var responseErr *hostedResponseError
+		if errors.As(err, &responseErr) {
+			fmt.Printf(T().HostedReleaseURLUnknown+"\n", provider.Name, tag, responseErr.Err)
+			results = append(results, result)
+			continue
+		}
+
+		result.Error = err.Error()
+		results = append(results, result)
+
+		var statusErr *httpStatusError
+		if !errors.As(err, &statusErr) {
+			fmt.Printf(T().HostedReleasesUnreachable+"\n", provider.Name, apiURL, err)
+			for _, skipped := range releases[i+1:] {
+				results = append(results, hostedReleaseResult{Tag: skipped.Tag, Error: err.Error()})
+			}
+			break
+		}
+		fmt.Printf(T().HostedReleaseFailed+"\n", provider.Name, tag, err)
+	}
+	return results
+}
+
+// hostedResponseError is a release created by the API with a response that could not be read
+type hostedResponseError struct {
+	Err error
+}
+
+func (e *hostedResponseError) Error() string {
+	return "unreadable response: " + e.Err.Error()
+}
+
+func (e *hostedResponseError) Unwrap() error {
+	return e.Err
+}
+
+// hostedTarget returns the API base URL and the repository, filling in what
+// the config leaves out from the origin remote
+func hostedTarget(config hostedReleaseConfig, provider hostedProvider) (string, string, error) {
+	apiURL, repository := config.APIURL, config.Repository
+	if apiURL != "" && repository != "" {
+		return strings.TrimSuffix(apiURL, "/"), repository, nil
+	}
+
+	remote, err := gitRepo().RemoteURL(runContext, "origin")
+	if err != nil {
+		return "", "", err
+	}
+	host, path, ok := parseRemoteURL(remote)
+	if !ok {
+		return "", "", fmt.Errorf(T().HostedRepositoryUnknown, remote)
+	}
+
+	if repository == "" {
+		repository = path
+	}
+	if apiURL == "" {
+		apiURL = provider.APIURL
+		if apiURL == "" {
+			apiURL = "https://" + host + provider.APIPath
+		}
+	}
+	return strings.TrimSuffix(apiURL, "/"), repository, nil
+}
+
+// parseRemoteURL returns the host and repository path of a remote URL such
+// as https://host/owner/repo.git or git@host:owner/repo.git
+func parseRemoteURL(remote string) (string, string, bool) {
+	var host, path string
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if address, rest, found := strings.Cut(remote, ":"); found && !strings.Contains(address, "/") && !strings.Contains(remote, "://") {
+		_, host, _ = strings.Cut(address, "@")
+		if host == "" {
+			host = address
+		}
+		path = rest
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return host, path, host != "" && strings.Contains(path, "/")
+}
+
+// releaseNotes builds the body of a hosted release from the released commits
+func releaseNotes(previous string, commits []rtag.CommitInfo) string {
+	var b strings.Builder
+	if previous != "" {
+		fmt.Fprintf(&b, "Changes since %s:\n\n", previous)
+	} else {
+		b.WriteString("Changes:\n\n")
+	}
+
+	if len(commits) == 0 {
+		b.WriteString("No new commits.\n")
+	}
+	for i, c := range commits {
+		if i == hostedChangelogLimit {
+			fmt.Fprintf(&b, "- ... and %d more\n", len(commits)-i)
+			break
+		}
+		fmt.Fprintf(&b, "- %s (%s)\n", c.Subject, shortCommit(c.Hash))
+	}
+	return b.String()
+}
+
+// postHostedRelease creates a release through the API of provider and
+// returns its web URL and the response status
+func postHostedRelease(provider, apiURL, repository, token, tag, notes string) (string, int, error) {
+	request := map[string]string{"tag_name": tag, "name": tag}
+	var endpoint string
+	switch provider {
+	case "gitlab":
+		endpoint = apiURL + "/projects/" + url.PathEscape(repository) + "/releases"
+		request["description"] = notes
+	default:
+		endpoint = apiURL + "/repos/" + repository + "/releases"
+		request["body"] = notes
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(runContext, hostedReleaseTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "rtag")
+	switch provider {
+	case "github":
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	case "gitlab":
+		req.Header.Set("PRIVATE-TOKEN", token)
+	case "gitea":
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := strings.TrimSpace(string(data))
+		if len(message) > 200 {
+			message = message[:200]
+		}
+		return "", resp.StatusCode, &httpStatusError{Status: resp.StatusCode, Body: message}
+	}
+
+	// GitHub and Gitea return html_url, GitLab the self link
+	var created struct {
+		HTMLURL string `json:"html_url"`
+		Links   struct {
+			Self string `json:"self"`
+		} `json:"_links"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return "", resp.StatusCode, &hostedResponseError{Err: err}
+	}
+	if created.HTMLURL == "" {
+		created.HTMLURL = created.Links.Self
+	}
+	return created.HTMLURL, resp.StatusCode, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+func runImport(cmd *cobra.Command, args []string) {
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().ImportFailed+"\n", err)
+		return
+	}
+
+	template := project.Naming()
+	if importPattern != "" {
+		if template, err = rtag.ParseTemplate(importPattern); err != nil {
+			fmt.Printf(T().ImportFailed+"\n", localizeError(err))
+			return
+		}
+	}
+
+	refs, err := listTagRefs()
+	if err != nil {
+		fmt.Printf(T().ImportFailed+"\n", err)
+		return
+	}
+
+	// 按组件归类匹配的 tags，保持创建时间顺序
+	var components []string
+	history := make(map[string][]rtag.TagRef)
+	for _, ref := range refs {
+		values, ok := template.Match(ref.Name)
+		if !ok {
+			continue
+		}
+
+		component := values["component"]
+		if _, exists := history[component]; !exists {
+			components = append(components, component)
+		}
+		history[component] = append(history[component], ref)
+	}
+
+	if len(components) == 0 {
+		fmt.Printf(T().NoTagsMatchTemplate+"\n", template)
+		return
+	}
+
+	tags, err := readTags()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	fmt.Printf(T().ImportedHistory+"\n", template)
+	for _, component := range components {
+		releases := history[component]
+		first, last := releases[0], releases[len(releases)-1]
+		fmt.Printf(T().ImportedComponent+"\n", component, len(releases), first.Name, last.Name)
+
+		if containsTag(tags, component) {
+			continue
+		}
+
+		if err := addTag(component); err != nil {
+			fmt.Printf("    "+T().AddTagFailed+"\n", err)
+		} else {
+			fmt.Printf("    "+T().AddTagSuccess+"\n", component)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// cleanupTimeout bounds the removal of unpushed tags, which also runs after the run context is cancelled
+const cleanupTimeout = 30 * time.Second
+
+// runContext is cancelled when rtag receives an interrupt or termination
+// signal. Every git operation of the run uses it.
+var runContext = context.Background()
+
+// unpushedTag is a tag created or moved by this run that has not been pushed yet
+type unpushedTag struct {
+	Dir   string // working directory of the repository, workspace runs span several
+	Name  string
+	Old   string // commit a moved tag pointed to before
+	Moved bool
+}
+
+var unpushedTags []unpushedTag
+
+// pushInterrupted is set when the run was cancelled while pushing
+var pushInterrupted bool
+
+// recordCreatedTag remembers a new tag so an interrupted run can remove it
+func recordCreatedTag(name string) {
+	dir, _ := os.Getwd()
+	unpushedTags = append(unpushedTags, unpushedTag{Dir: dir, Name: name})
+}
+
+// recordMovedTag remembers where a force-moved tag pointed before, "" if it is new
+func recordMovedTag(name, old string) {
+	dir, _ := os.Getwd()
+	unpushedTags = append(unpushedTags, unpushedTag{Dir: dir, Name: name, Old: old, Moved: old != ""})
+}
+
+// createTag creates a tag and records it for cleanup
+func createTag(name, target string, opts rtag.TagOptions) error {
+	if err := gitRepo().CreateTag(runContext, name, target, opts); err != nil {
+		return err
+	}
+	recordCreatedTag(name)
+	return nil
+}
+
+// disableGitPrompts makes git fail instead of waiting for credentials when
+// nobody can answer, e.g. in CI. Interactive runs keep prompting, and an
+// explicit GIT_TERMINAL_PROMPT is left as is.
+func disableGitPrompts() {
+	if _, set := os.LookupEnv("GIT_TERMINAL_PROMPT"); set {
+		return
+	}
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return
+	}
+	os.Setenv("GIT_TERMINAL_PROMPT", "0")
+}
+
+// checkPush tells, before anything is created, that the git backend cannot
+// push to origin, e.g. the native backend and a remote on the network
+func checkPush() bool {
+	if err := gitRepo().CheckPush(runContext, "origin"); err != nil {
+		fmt.Printf(T().PushNotPossible+"\n", err)
+		return false
+	}
+	return true
+}
+
+// pushRefs pushes refspecs to origin, retrying transient failures, and
+// forgets the tags that were pushed
+func pushRefs(refspecs []string) error {
+	// Nothing reached the remote if the run was cancelled before the push
+	if err := runContext.Err(); err != nil {
+		return err
+	}
+
+	result, err := rtag.PushWithRetry(runContext, gitRepo(), "origin", refspecs, pushRetryPolicy())
+	printPushAttempts(result)
+	if err != nil && runContext.Err() != nil {
+		pushInterrupted = true
+	}
+
+	dir, _ := os.Getwd()
+	pushed := make(map[string]bool)
+	for _, refspec := range result.Pushed {
+		_, dst, _ := strings.Cut(refspec, ":")
+		pushed[strings.TrimPrefix(dst, "refs/tags/")] = true
+	}
+
+	var remaining []unpushedTag
+	for _, tag := range unpushedTags {
+		if tag.Dir != dir || !pushed[tag.Name] {
+			remaining = append(remaining, tag)
+		}
+	}
+	unpushedTags = remaining
+	return err
+}
+
+// cleanupInterruptedRun handles the tags of a cancelled run. Tags that never
+// reached the push are removed and moved tags are restored. When the push
+// itself was interrupted the remote may already have some of the tags, so
+// they are kept and reported instead.
+func cleanupInterruptedRun() {
+	if len(unpushedTags) == 0 {
+		return
+	}
+
+	if pushInterrupted {
+		names := make([]string, len(unpushedTags))
+		for i, tag := range unpushedTags {
+			names[i] = tag.Name
+		}
+		fmt.Printf("\n"+T().InterruptedDuringPush+"\n", strings.Join(names, ", "))
+		return
+	}
+
+	fmt.Println("\n" + T().Interrupted)
+	discardUnpushedTags()
+}
+
+// discardUnpushedTags removes the tags created by this run that were not
+// pushed and restores the moved ones. It still runs when the run was
+// interrupted, bounded by cleanupTimeout instead.
+func discardUnpushedTags() {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(runContext), cleanupTimeout)
+	defer cancel()
+
+	// Undo in reverse order so a tag moved twice ends up at its original commit
+	for i := len(unpushedTags) - 1; i >= 0; i-- {
+		tag := unpushedTags[i]
+		if cwd, _ := os.Getwd(); tag.Dir != cwd {
+			if err := enterRepo(tag.Dir); err != nil {
+				fmt.Printf(T().InterruptedCleanupFailed+"\n", tag.Name, err)
+				continue
+			}
+		}
+
+		if tag.Moved {
+			if err := gitRepo().CreateTag(ctx, tag.Name, tag.Old, rtag.TagOptions{Force: true}); err != nil {
+				fmt.Printf(T().InterruptedCleanupFailed+"\n", tag.Name, err)
+			} else {
+				fmt.Printf(T().InterruptedTagRestored+"\n", tag.Name, shortCommit(tag.Old))
+			}
+			continue
+		}
+
+		if err := gitRepo().DeleteTags(ctx, []string{tag.Name}); err != nil {
+			fmt.Printf(T().InterruptedCleanupFailed+"\n", tag.Name, err)
+		} else {
+			fmt.Printf(T().InterruptedTagRemoved+"\n", tag.Name)
+		}
+	}
+	unpushedTags = nil
+}
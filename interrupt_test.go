@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+func TestDiscardUnpushedTags(t *testing.T) {
+	backend := useMemoryBackend(t)
+	first, err := backend.ResolveRef(runContext, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := backend.Commit("second", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	third, err := backend.Commit("third", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// release-latest-api and release-202401010000-web existed before the run,
+	// which moved release-latest-api twice and created the other tags
+	if err := backend.CreateTag(runContext, "release-latest-api", first, rtag.TagOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.CreateTag(runContext, "release-202401010000-web", first, rtag.TagOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []struct{ name, target, old string }{
+		{"release-latest-api", second, first},
+		{"release-latest-api", third, second},
+		{"release-latest-web", third, ""},
+	} {
+		if err := backend.CreateTag(runContext, tag.name, tag.target, rtag.TagOptions{Force: true}); err != nil {
+			t.Fatal(err)
+		}
+		recordMovedTag(tag.name, tag.old)
+	}
+	if err := createTag("release-202401010000-api", third, rtag.TagOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { unpushedTags = nil })
+
+	// Cleaning up after an interrupt runs with the run context cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	previous := runContext
+	runContext = ctx
+	t.Cleanup(func() { runContext = previous })
+	cancel()
+
+	discardUnpushedTags()
+
+	if len(unpushedTags) != 0 {
+		t.Errorf("unpushedTags = %+v after the cleanup", unpushedTags)
+	}
+	for name, want := range map[string]string{
+		"release-latest-api":       first,
+		"release-202401010000-web": first,
+		"release-latest-web":       "",
+		"release-202401010000-api": "",
+	} {
+		exists, err := backend.RefExists(context.Background(), "refs/tags/"+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == "" {
+			if exists {
+				t.Errorf("%s was not removed", name)
+			}
+			continue
+		}
+		if got, err := backend.ResolveRef(context.Background(), name); err != nil || got != want {
+			t.Errorf("%s points to %s, %v, want %s", name, got, err, want)
+		}
+	}
+}
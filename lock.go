@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// lockRelease locks tags on the remote when release locks are enabled. It
+// returns the function releasing the locks, and false if the release must
+// not go ahead.
+func lockRelease(tags []string, config projectConfig) (func(), bool) {
+	if !config.ReleaseLock && !pushLock {
+		return func() {}, true
+	}
+
+	ttl, err := time.ParseDuration(config.ReleaseLockTTL)
+	if err != nil || ttl <= 0 {
+		fmt.Printf(T().LockFailed+"\n", fmt.Errorf(T().InvalidLockTTL, config.ReleaseLockTTL))
+		return nil, false
+	}
+
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().LockFailed+"\n", err)
+		return nil, false
+	}
+
+	fmt.Printf(T().AcquiringLocks+"\n", strings.Join(tags, ", "))
+	locks, err := project.Lock(runContext, tags, releaseLockHolder(), ttl)
+	if err != nil {
+		var lockErr *rtag.LockError
+		if errors.As(err, &lockErr) {
+			lock := lockErr.Lock
+			fmt.Printf(T().ComponentLocked+"\n", lock.Component, lock.Holder,
+				lock.Acquired.Local().Format(time.DateTime), lock.Expires.Local().Format(time.DateTime), lock.Component)
+		} else {
+			fmt.Printf(T().LockFailed+"\n", err)
+		}
+		return nil, false
+	}
+
+	// The locks are released even after Ctrl-C, which cancels runContext.
+	// Releasing them twice is a no-op, so callers may unlock early and defer.
+	released := false
+	return func() {
+		if released {
+			return
+		}
+		released = true
+
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		if err := project.Unlock(ctx, locks); err != nil {
+			fmt.Printf(T().UnlockFailed+"\n", err)
+		}
+	}, true
+}
+
+// releaseLockHolder identifies this release in the lock, RTAG_LOCK_HOLDER
+// overrides the default user@host, e.g. with a CI job URL
+func releaseLockHolder() string {
+	if holder := os.Getenv("RTAG_LOCK_HOLDER"); holder != "" {
+		return holder
+	}
+
+	name := "unknown"
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s@%s (pid %d)", name, host, os.Getpid())
+}
+
+func runLockStatus(cmd *cobra.Command, args []string) {
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().ReadLocksFailed+"\n", err)
+		return
+	}
+
+	locks, err := project.Locks(runContext)
+	if err != nil {
+		fmt.Printf(T().ReadLocksFailed+"\n", err)
+		return
+	}
+
+	if len(locks) == 0 {
+		fmt.Println(T().NoLocks)
+		return
+	}
+
+	now := time.Now()
+	for _, lock := range locks {
+		format := T().LockHeld
+		if lock.Expired(now) {
+			format = T().LockExpired
+		}
+		fmt.Printf(format+"\n", lock.Component, lock.Holder,
+			lock.Acquired.Local().Format(time.DateTime), lock.Expires.Local().Format(time.DateTime))
+	}
+}
+
+func runLockBreak(cmd *cobra.Command, args []string) {
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().ReadLocksFailed+"\n", err)
+		return
+	}
+
+	for _, tag := range args {
+		err := project.BreakLock(runContext, tag)
+		switch {
+		case errors.Is(err, rtag.ErrNotFound):
+			fmt.Printf(T().NotLocked+"\n", tag)
+		case err != nil:
+			fmt.Printf(T().BreakLockFailed+"\n", tag, err)
+		default:
+			fmt.Printf(T().LockBroken+"\n", tag)
+		}
+	}
+}
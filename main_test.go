@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// runMainEnv makes the test binary run rtag instead of the tests, so that
+// tests can check the output and exit status of a command
+const runMainEnv = "RTAG_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	// Tests calling rtag functions directly read the English messages
+	SetLanguage(LangEN)
+	os.Exit(m.Run())
+}
+
+// runRtag runs rtag with args in dir and returns its output and exit status
+func runRtag(t *testing.T, dir string, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMainEnv+"=1", "RTAG_LANG=en")
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(output), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output), 0
+}
+
+// runGit runs git in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// newTestRepo creates a repository on main with one commit, an origin, and
+// the given .rtag and .rtag.json. git runs isolated from the user configuration.
+func newTestRepo(t *testing.T, tags, config string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, role := range []string{"AUTHOR", "COMMITTER"} {
+		t.Setenv("GIT_"+role+"_NAME", "Tester")
+		t.Setenv("GIT_"+role+"_EMAIL", "tester@example.com")
+	}
+
+	dir, remote := t.TempDir(), t.TempDir()
+	runGit(t, remote, "init", "-q", "--bare")
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "remote", "add", "origin", remote)
+	for name, content := range map[string]string{".rtag": tags, ".rtag.json": config} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+// useMemoryBackend makes rtag functions run against an in-memory repository,
+// rooted in a temporary directory, with one commit tagged with tags
+func useMemoryBackend(t *testing.T, tags ...string) *rtag.MemoryBackend {
+	t.Helper()
+	backend := rtag.NewMemoryBackend(t.TempDir())
+	commit, err := backend.Commit("initial", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range tags {
+		if err := backend.CreateTag(runContext, tag, commit, rtag.TagOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous, previousRoot := currentGitBackend, repoRootCache
+	currentGitBackend, repoRootCache = backend, nil
+	t.Cleanup(func() { currentGitBackend, repoRootCache = previous, previousRoot })
+	return backend
+}
@@ -26,6 +26,7 @@ type Messages struct {
 
 	// Flag descriptions
 	PushAllFlag string
+	FileFlag    string
 
 	// User messages
 	ErrorReadingRtagFile    string
@@ -50,6 +51,7 @@ type Messages struct {
 	CreateTagFailed         string
 	PushingTagsToRemote     string
 	PushTagsFailed          string
+	PushNotPossible         string
 	PushTagsSuccess         string
 	TagAlreadyExists        string
 	TagNotExist             string
@@ -64,6 +66,263 @@ type Messages struct {
 	LanguagePreferenceSaved string
 	LanguageChangeNote      string
 	InvalidLanguage         string
+
+	// Rollback command messages
+	RollbackShort           string
+	RollbackLong            string
+	RollbackToFlag          string
+	ListReleasesFailed      string
+	NoReleasesFound         string
+	RollingBack             string
+	RollbackFailed          string
+	RollbackSuccess         string
+	RollbackTargetIsCurrent string
+	RollbackTagExists       string
+	ReleaseNotFound         string
+	NoPreviousRelease       string
+
+	// Yank and history command messages
+	YankShort            string
+	YankLong             string
+	YankReasonFlag       string
+	YankReasonRequired   string
+	YankFailed           string
+	YankSuccess          string
+	NotAReleaseTag       string
+	ReleaseTagNotExist   string
+	ReleaseAlreadyYanked string
+	YankNotPushed        string
+	ReleaseIsYanked      string
+	HistoryShort         string
+	HistoryLong          string
+	LatestShort          string
+	LatestLong           string
+	ReleaseHistory       string
+	YankedMarker         string
+
+	// Promote command messages
+	PromoteShort               string
+	PromoteLong                string
+	PromoteToFlag              string
+	PromoteTargetRequired      string
+	LoadConfigFailed           string
+	PromoteFailed              string
+	Promoting                  string
+	PromoteSuccess             string
+	AlreadyPromoted            string
+	UnknownEnvironment         string
+	EnvironmentTagsAreReleases string
+	NotPromotedTo              string
+	OnlyYankedPromotions       string
+
+	// Floating tag messages
+	PushFloatingFlag        string
+	UpdateFloatingTag       string
+	UpdateFloatingTagFailed string
+
+	// Group and selector messages
+	AllGroups          string
+	SelectTagsFailed   string
+	InvalidTagName     string
+	InvalidTagFileLine string
+	GroupNotExist      string
+	GroupTagNotExist   string
+	InvalidPattern     string
+	NoTagsMatch        string
+
+	// Release train messages
+	TrainShort          string
+	TrainLong           string
+	ResolveCommitFailed string
+	StartTrain          string
+	TrainIncomplete     string
+	TrainNotPushed      string
+	TrainSuccess        string
+
+	// Dependency messages
+	PushCascadeFlag string
+	AllDependencies string
+	DependencyCycle string
+
+	// Go module messages
+	GoModShort                 string
+	GoModLong                  string
+	GoModDiscoverShort         string
+	GoModDiscoverLong          string
+	GoModTagShort              string
+	GoModTagLong               string
+	GoModBumpFlag              string
+	DiscoverGoModulesFailed    string
+	NoGoModulesFound           string
+	GoModuleRegistered         string
+	GoModuleAlreadyRegistered  string
+	GoModuleTagFailed          string
+	GoModuleNoModuleLine       string
+	InvalidGoVersion           string
+	InvalidBump                string
+	GoModuleMajorMismatch      string
+	GoModuleMajorSuffixMissing string
+
+	// Discovery messages
+	InitDiscoverFlag         string
+	DiscoverComponentsFailed string
+	NoComponentsDiscovered   string
+	ConfirmDiscoveredTag     string
+
+	// Tag template and import messages
+	InvalidTemplate           string
+	UnknownTemplateField      string
+	TemplateMissingComponent  string
+	NamingTemplateFields      string
+	MigrateToInactiveTemplate string
+	ImportShort               string
+	ImportLong                string
+	ImportPatternFlag         string
+	ImportFailed              string
+	NoTagsMatchTemplate       string
+	ImportedHistory           string
+	ImportedComponent         string
+
+	// Tag migration messages
+	MigrateTagsShort         string
+	MigrateTagsLong          string
+	MigrateFromFlag          string
+	MigrateToFlag            string
+	MigrateDeleteOldFlag     string
+	MigrateDryRunFlag        string
+	MigrateReportFlag        string
+	MigrateFailed            string
+	MigrateDryRun            string
+	MigrateSuccess           string
+	TemplateFieldUnavailable string
+	MigrationConflict        string
+	MigrateKeepsYank         string
+	MigrateYankFailed        string
+	ReadTagFailed            string
+	DeletingOldTags          string
+	DeleteOldTagsFailed      string
+	WriteReportFailed        string
+	ReportWritten            string
+
+	// Workspace messages
+	WorkspaceShort           string
+	WorkspaceLong            string
+	WorkspaceListShort       string
+	WorkspaceStatusShort     string
+	WorkspacePushShort       string
+	WorkspaceFileFlag        string
+	WorkspaceReportFlag      string
+	WorkspaceFileOverride    string
+	ReadWorkspaceFailed      string
+	WorkspaceEmpty           string
+	WorkspaceRepo            string
+	WorkspaceRepoFailed      string
+	WorkspaceBranchClean     string
+	WorkspaceBranchDirty     string
+	WorkspacePushRequiresAll string
+	WorkspacePushSummary     string
+
+	// Interrupt and timeout messages
+	TimeoutFlag              string
+	ReleaseFailed            string
+	Interrupted              string
+	InterruptedTagRemoved    string
+	InterruptedTagRestored   string
+	InterruptedCleanupFailed string
+	InterruptedDuringPush    string
+
+	// Push retry messages
+	RetriesFlag          string
+	PushAttempts         string
+	PushAttemptSucceeded string
+	PushAttemptRetrying  string
+	PushAttemptFailed    string
+
+	// Tag file lock messages
+	TagFileLocked string
+
+	// Release lock messages
+	LockShort       string
+	LockStatusShort string
+	LockBreakShort  string
+	PushLockFlag    string
+	AcquiringLocks  string
+	ComponentLocked string
+	LockFailed      string
+	UnlockFailed    string
+	InvalidLockTTL  string
+	ReadLocksFailed string
+	NoLocks         string
+	LockHeld        string
+	LockExpired     string
+	LockBroken      string
+	NotLocked       string
+	BreakLockFailed string
+
+	// Release policy messages
+	PolicyShort          string
+	PolicyCheckShort     string
+	PolicyCheckFailed    string
+	PolicyInvalidSetting string
+	PolicyViolated       string
+	PolicyPassed         string
+	PolicyBranch         string
+	PolicyCleanTree      string
+	PolicyMinInterval    string
+	PolicyApprovals      string
+	PolicyReleasers      string
+	PolicyDays           string
+	PolicyHours          string
+
+	// Freeze messages
+	FreezeShort          string
+	FreezeListShort      string
+	FreezeDaysFlag       string
+	OverrideFreezeFlag   string
+	FreezeReasonFlag     string
+	FreezeCheckFailed    string
+	ReleaseFrozen        string
+	FreezeWindowLine     string
+	FreezeActive         string
+	FreezeAllComponents  string
+	FreezeOverrideHint   string
+	FreezeReasonRequired string
+	FreezeOverridden     string
+	NoFreezes            string
+	FreezeListHeader     string
+
+	// Hook messages
+	PushReportFlag       string
+	TrainReportFlag      string
+	RunningHook          string
+	RunningComponentHook string
+	HookFailed           string
+	InvalidHookTimeout   string
+	ReleaseAbortedByHook string
+
+	// Webhook messages
+	NotifyShort              string
+	NotifyTestShort          string
+	NotifyURLFlag            string
+	NotifyAllFlag            string
+	NoWebhooks               string
+	NotifyTestTargetRequired string
+	NotifyWebhookFlag        string
+	NotifyWebhookNotFound    string
+	WebhookSent              string
+	WebhookRetrying          string
+	WebhookFailed            string
+	WebhookSecretMissing     string
+
+	// Hosted release messages
+	HostedReleaseCreated      string
+	HostedReleaseURLUnknown   string
+	HostedReleaseFailed       string
+	HostedReleasesFailed      string
+	HostedReleasesUnreachable string
+	HostedReleaseTokenMissing string
+	UnknownHostedProvider     string
+	HostedRepositoryUnknown   string
 }
 
 // GetAllMessages returns messages for all supported languages
@@ -89,18 +348,19 @@ func getEnglishMessages() Messages {
 		AddLong:  "Add a new tag to the .rtag file. If no tag is provided, interactive mode will be used.",
 
 		PushShort: "Push tags to remote repository",
-		PushLong:  "Push tags to remote repository. Use --all flag to push all tags.",
+		PushLong:  "Push tags to remote repository. Tags can be given by name, as a group such as @backend or as a glob pattern such as 'worker-*', and are released with one shared timestamp. Use --all flag to push all tags.",
 
 		ListShort: "List all tags",
 		ListLong:  "List all tags from the .rtag file.",
 
 		RmShort: "Remove a tag",
-		RmLong:  "Remove a tag from the .rtag file.",
+		RmLong:  "Remove tags from the .rtag file. Tags can be given by name, as a group such as @backend or as a glob pattern.",
 
 		LangShort: "Set or display current language",
 		LangLong:  "Set the interface language or display current language settings.",
 
 		PushAllFlag: "Push all tags",
+		FileFlag:    "Path to the .rtag file (default: .rtag in the repository root, or RTAG_FILE)",
 
 		ErrorReadingRtagFile:    "Error reading .rtag file: %v",
 		RtagFileEmptyOrNotExist: ".rtag file is empty or does not exist",
@@ -124,6 +384,7 @@ func getEnglishMessages() Messages {
 		CreateTagFailed:         "Failed to create tag %s: %v",
 		PushingTagsToRemote:     "Pushing tags to remote repository...",
 		PushTagsFailed:          "Failed to push tags: %v",
+		PushNotPossible:         "Cannot push to origin, nothing was created: %v",
 		PushTagsSuccess:         "Successfully pushed all tags",
 		TagAlreadyExists:        "tag '%s' already exists",
 		TagNotExist:             "tag '%s' does not exist",
@@ -137,6 +398,242 @@ func getEnglishMessages() Messages {
 		LanguagePreferenceSaved: "Language preference saved successfully.",
 		LanguageChangeNote:      "Note: Language change will take effect on next command execution.",
 		InvalidLanguage:         "Invalid language '%s'. Supported languages: %s",
+
+		RollbackShort:           "Roll back a tag to a previous release",
+		RollbackLong:            "Create a new release tag pointing at the commit of a previous release of the tag. By default the newest release with a different commit is used, or choose one with --to.",
+		RollbackToFlag:          "Release tag to roll back to (defaults to the previous release)",
+		ListReleasesFailed:      "Failed to list releases: %v",
+		NoReleasesFound:         "No releases found for %s",
+		RollingBack:             "Rolling back %s from %s to %s...",
+		RollbackFailed:          "Failed to roll back: %v",
+		RollbackSuccess:         "Successfully rolled back %s to %s as %s",
+		RollbackTargetIsCurrent: "release '%s' is the current release",
+		RollbackTagExists:       "release tag %s already exists, another release was made this minute, retry in a minute",
+		ReleaseNotFound:         "release '%s' not found for %s",
+		NoPreviousRelease:       "no previous release of %s to roll back to",
+
+		YankShort:            "Mark a release as yanked",
+		YankLong:             "Mark a release tag as yanked without deleting it. Yanked releases are annotated in history and skipped by latest and rollback.",
+		YankReasonFlag:       "Reason for yanking the release",
+		YankReasonRequired:   "Please provide a reason with --reason",
+		YankFailed:           "Failed to yank release: %v",
+		YankSuccess:          "Successfully yanked release: %s",
+		NotAReleaseTag:       "'%s' is not a release tag",
+		ReleaseTagNotExist:   "release tag '%s' does not exist",
+		ReleaseAlreadyYanked: "release '%s' is already yanked",
+		YankNotPushed:        "release '%s' was yanked locally but the yank was not pushed, pushing it now",
+		ReleaseIsYanked:      "release '%s' is yanked",
+		HistoryShort:         "Show the release history of a tag",
+		HistoryLong:          "Show all releases of a tag from newest to oldest, annotating yanked releases.",
+		LatestShort:          "Print the latest release of a tag",
+		LatestLong:           "Print the newest release tag of a tag that has not been yanked.",
+		ReleaseHistory:       "Release history of %s:",
+		YankedMarker:         "[yanked: %s]",
+
+		PromoteShort:               "Promote a tag to the next environment",
+		PromoteLong:                "Create an environment tag on the commit of the tag's release in the previous environment. The first environment takes the latest release, environments are configured in .rtag.json and cannot be skipped.",
+		PromoteToFlag:              "Environment to promote to",
+		PromoteTargetRequired:      "Please specify the environment with --to",
+		LoadConfigFailed:           "Failed to load project config: %v",
+		PromoteFailed:              "Failed to promote: %v",
+		Promoting:                  "Promoting %s to %s...",
+		PromoteSuccess:             "Successfully promoted %s to %s as %s",
+		AlreadyPromoted:            "%s is already current in %s as %s",
+		UnknownEnvironment:         "unknown environment '%s'. Configured environments: %s",
+		EnvironmentTagsAreReleases: "environment '%s' cannot be promoted to: its tags would be taken for release tags",
+		NotPromotedTo:              "%s has not been promoted to %s yet",
+		OnlyYankedPromotions:       "every release of %s promoted to %s was yanked",
+
+		PushFloatingFlag:        "Also move the release-latest-{tag} floating tags",
+		UpdateFloatingTag:       "Moving floating tag %s to %s",
+		UpdateFloatingTagFailed: "Failed to move floating tag %s: %v",
+
+		AllGroups:          "All groups:",
+		SelectTagsFailed:   "Failed to select tags: %v",
+		InvalidTagName:     "tag '%s' must not start with '@' or contain ':', ',', whitespace or glob characters",
+		InvalidTagFileLine: "%s: invalid line %q, expected \"[group] name: tag1, tag2\" or \"[depends] tag -> dep1, dep2\"",
+		GroupNotExist:      "group '%s' does not exist",
+		GroupTagNotExist:   "group '%s' contains tag '%s' which does not exist",
+		InvalidPattern:     "invalid pattern '%s': %v",
+		NoTagsMatch:        "no tags match '%s'",
+
+		TrainShort:          "Release a group of tags as one release train",
+		TrainLong:           "Create release tags for every tag in a group plus an umbrella train-YYYYMMDDHHMM-{group} tag whose annotation lists each release tag and commit.",
+		ResolveCommitFailed: "Failed to resolve commit of %s: %v",
+		StartTrain:          "Starting release train %s (timestamp: %s)...",
+		TrainIncomplete:     "Not all tags of %s could be tagged, removing the tags created by this run",
+		TrainNotPushed:      "The train %s was not pushed, removing its local tags so it can be run again",
+		TrainSuccess:        "Successfully released train %s as %s",
+
+		PushCascadeFlag: "Also push the tags that depend on the given tags",
+		AllDependencies: "All dependencies:",
+		DependencyCycle: "dependency cycle: %s",
+
+		GoModShort:                 "Tag nested Go modules",
+		GoModLong:                  "Register nested Go modules as tags and create module tags in the path/vX.Y.Z format expected by the Go toolchain.",
+		GoModDiscoverShort:         "Register nested Go modules as tags",
+		GoModDiscoverLong:          "Find nested go.mod files and add their directories to the .rtag file.",
+		GoModTagShort:              "Create a Go module version tag",
+		GoModTagLong:               "Create and push a tag such as libs/auth/v1.4.0 for a registered Go module. Without a version the latest release is bumped with --bump. Versions must match the /vN suffix of the module path.",
+		GoModBumpFlag:              "Version part to bump when no version is given (major, minor or patch)",
+		DiscoverGoModulesFailed:    "Failed to discover Go modules: %v",
+		NoGoModulesFound:           "No nested Go modules found",
+		GoModuleRegistered:         "Registered Go module %s (%s)",
+		GoModuleAlreadyRegistered:  "Go module %s (%s) is already registered",
+		GoModuleTagFailed:          "Failed to tag Go module: %v",
+		GoModuleNoModuleLine:       "%s has no module directive",
+		InvalidGoVersion:           "invalid version '%s', expected vMAJOR.MINOR.PATCH",
+		InvalidBump:                "invalid bump '%s', expected major, minor or patch",
+		GoModuleMajorMismatch:      "version %s does not match module path %s, which requires major version %d",
+		GoModuleMajorSuffixMissing: "version %s requires module path %s to end in /v%d",
+
+		InitDiscoverFlag:         "Propose tags from cmd/* directories, Go modules, Dockerfiles and existing release tags",
+		DiscoverComponentsFailed: "Failed to discover tags: %v",
+		NoComponentsDiscovered:   "No new tags discovered",
+		ConfirmDiscoveredTag:     "Add tag '%s' (from %s)? (y/n): ",
+
+		InvalidTemplate:           "invalid tag template '%s'",
+		UnknownTemplateField:      "unknown placeholder '{%s}' in tag template '%s', supported: {component}, {timestamp}, {version}",
+		TemplateMissingComponent:  "tag template '%s' must contain {component}",
+		NamingTemplateFields:      "release tag template '%s' must contain {component} and {timestamp}, and no {version}",
+		MigrateToInactiveTemplate: "'%s' is not the tag_template of %s ('%s'): set it there first, otherwise history, latest, rollback and yank would not find the migrated releases",
+		ImportShort:               "Import tags and history from existing git tags",
+		ImportLong:                "Scan existing git tags matching a template such as '{component}-v{version}', add the detected tags to the .rtag file and report their release history.",
+		ImportPatternFlag:         "Tag template with {component} and optional {timestamp} or {version} placeholders, tag_template of .rtag.json by default",
+		ImportFailed:              "Failed to import tags: %v",
+		NoTagsMatchTemplate:       "No git tags match '%s'",
+		ImportedHistory:           "Detected history for '%s':",
+		ImportedComponent:         "  - %s: %d releases (%s ... %s)",
+
+		MigrateTagsShort:         "Migrate release tags to a new naming scheme",
+		MigrateTagsLong:          "Create tags named by the --to template on the commits of all tags matching the --from template, preserving annotations and dates. Old tags can be deleted locally and remotely with --delete-old.",
+		MigrateFromFlag:          "Template of the existing tags, e.g. '{component}-v{version}'",
+		MigrateToFlag:            "Template of the new tags, tag_template of .rtag.json by default",
+		MigrateDeleteOldFlag:     "Delete the old tags locally and remotely after migrating",
+		MigrateDryRunFlag:        "Only show the tag mapping without changing anything",
+		MigrateReportFlag:        "Write the tag mapping as JSON to this file",
+		MigrateFailed:            "Failed to migrate tags: %v",
+		MigrateDryRun:            "Dry run: %d tags would be migrated",
+		MigrateSuccess:           "Successfully migrated %d tags",
+		TemplateFieldUnavailable: "placeholder '{%s}' is not available from template '%s'",
+		MigrationConflict:        "tags '%s' and '%s' would both be migrated to '%s'",
+		MigrateKeepsYank:         "[yanked]",
+		MigrateYankFailed:        "Failed to carry the yank of %s over to %s: %v",
+		ReadTagFailed:            "failed to read tag '%s'",
+		DeletingOldTags:          "Deleting old tags...",
+		DeleteOldTagsFailed:      "Failed to delete old tags: %v",
+		WriteReportFailed:        "Failed to write report: %v",
+		ReportWritten:            "Report written to %s",
+
+		WorkspaceShort:           "Operate on a workspace of several repositories",
+		WorkspaceLong:            "Run list, status and push across all repositories listed in the workspace file, one path per line.",
+		WorkspaceListShort:       "List the tags of every repository",
+		WorkspaceStatusShort:     "Show branch, local changes and latest releases of every repository",
+		WorkspacePushShort:       "Push the tags of every repository with one shared timestamp",
+		WorkspaceFileFlag:        "Path to the workspace file",
+		WorkspaceReportFlag:      "Write a combined JSON report to this file",
+		WorkspaceFileOverride:    "--file and RTAG_FILE cannot be used with workspaces, each repository uses its own .rtag file",
+		ReadWorkspaceFailed:      "Failed to read workspace: %v",
+		WorkspaceEmpty:           "The workspace contains no repositories",
+		WorkspaceRepo:            "== %s",
+		WorkspaceRepoFailed:      "Failed in %s: %s",
+		WorkspaceBranchClean:     "  branch %s, no local changes",
+		WorkspaceBranchDirty:     "  branch %s, %d local changes",
+		WorkspacePushRequiresAll: "Please use --all to push the tags of every repository",
+		WorkspacePushSummary:     "Pushed %d of %d repositories (timestamp: %s)",
+
+		TimeoutFlag:              "Cancel a git operation that takes longer than this (e.g. 2m, 0 for no limit)",
+		ReleaseFailed:            "Release stopped: %v",
+		Interrupted:              "Interrupted, cleaning up tags created by this run that were not pushed...",
+		InterruptedTagRemoved:    "  - removed local tag %s",
+		InterruptedTagRestored:   "  - restored %s to %s",
+		InterruptedCleanupFailed: "  - failed to clean up %s: %v",
+		InterruptedDuringPush:    "The push was interrupted, these tags may already exist on the remote: %s",
+
+		RetriesFlag:          "Times to retry a push that failed with a transient error (default from push_retries in .rtag.json, 3)",
+		PushAttempts:         "Push attempts:",
+		PushAttemptSucceeded: "  #%d pushed %d ref(s)",
+		PushAttemptRetrying:  "  #%d failed, retrying %d ref(s) in %s: %v",
+		PushAttemptFailed:    "  #%d failed: %v",
+
+		TagFileLocked: "%s is being updated by another rtag process, remove %s.lock if none is running",
+
+		LockShort:       "Show or break the release locks on the remote",
+		LockStatusShort: "List the components being released and who holds their lock",
+		LockBreakShort:  "Remove the release lock of components, e.g. after a crashed release",
+		PushLockFlag:    "Lock the components on the remote while releasing them (also release_lock in .rtag.json)",
+		AcquiringLocks:  "Locking %s on the remote...",
+		ComponentLocked: "%s is being released by %s since %s (lock expires %s), try again later or run 'rtag lock break %s'",
+		LockFailed:      "Failed to lock the release: %v",
+		UnlockFailed:    "Failed to release the locks, they expire on their own: %v",
+		InvalidLockTTL:  "invalid release_lock_ttl %q in .rtag.json",
+		ReadLocksFailed: "Failed to read the release locks: %v",
+		NoLocks:         "No component is locked",
+		LockHeld:        "  - %s: %s, since %s, expires %s",
+		LockExpired:     "  - %s: %s, since %s, expired %s",
+		LockBroken:      "Removed the release lock of %s",
+		NotLocked:       "%s is not locked",
+		BreakLockFailed: "Failed to remove the release lock of %s: %v",
+
+		PolicyShort:          "Evaluate the release policy of .rtag.json",
+		PolicyCheckShort:     "Check the release policy for tags without releasing them",
+		PolicyCheckFailed:    "Failed to check the release policy: %v",
+		PolicyInvalidSetting: "invalid policy setting %s: %q",
+		PolicyViolated:       "The release policy does not allow this release:",
+		PolicyPassed:         "The release policy allows releasing %s",
+		PolicyBranch:         "  - branch %s is not allowed for releases, allowed: %s",
+		PolicyCleanTree:      "  - the working tree has %s uncommitted change(s), it must be clean",
+		PolicyMinInterval:    "  - %s was released %s ago (%s), at least %s must pass between releases",
+		PolicyApprovals:      "  - the released commit has %s Approved-by trailer(s), %s required",
+		PolicyReleasers:      "  - %s is not allowed to release, allowed: %s",
+		PolicyDays:           "  - releases are not allowed on %s, allowed days: %s",
+		PolicyHours:          "  - releases are not allowed at %s, allowed hours: %s",
+
+		FreezeShort:          "Show the release freezes of .rtag.json",
+		FreezeListShort:      "List the current and upcoming release freezes",
+		FreezeDaysFlag:       "Number of days ahead to show",
+		OverrideFreezeFlag:   "Release during a freeze, requires --reason",
+		FreezeReasonFlag:     "Reason for overriding the freeze, recorded in the tag annotation",
+		FreezeCheckFailed:    "Failed to check the release freezes: %v",
+		ReleaseFrozen:        "Releases are frozen:",
+		FreezeWindowLine:     "  - %s: %s - %s (%s)",
+		FreezeActive:         "[active]",
+		FreezeAllComponents:  "all components",
+		FreezeOverrideHint:   "Use --override-freeze --reason \"...\" to release anyway",
+		FreezeReasonRequired: "--override-freeze requires a --reason",
+		FreezeOverridden:     "Overriding freeze %s: %s",
+		NoFreezes:            "No release freezes in the next %d days",
+		FreezeListHeader:     "Release freezes in the next %d days:",
+
+		PushReportFlag:       "Write a JSON report of the release, including hook output, to this file",
+		TrainReportFlag:      "Write a JSON report of the train, including hook output, hosted releases and webhook deliveries, to this file",
+		RunningHook:          "Running %s hook: %s",
+		RunningComponentHook: "Running %s hook: %s (%s)",
+		HookFailed:           "Hook %s failed: %v",
+		InvalidHookTimeout:   "invalid hook timeout %q",
+		ReleaseAbortedByHook: "Release aborted by a pre_release hook, no tag was created",
+
+		NotifyShort:              "Manage the webhooks notified after releases",
+		NotifyTestShort:          "Send a test payload to the configured webhooks",
+		NotifyURLFlag:            "Send the test payload to this URL instead of the configured webhooks",
+		NotifyAllFlag:            "Send the test payload to every configured webhook, production ones included",
+		NoWebhooks:               "No webhooks configured in .rtag.json",
+		NotifyTestTargetRequired: "Pass --url to send the test payload to one endpoint, or --all to send it to the %d configured webhooks",
+		NotifyWebhookFlag:        "Number of the configured webhook whose body, headers, content type and secret --url uses",
+		NotifyWebhookNotFound:    "Webhook %d does not exist, %d are configured",
+		WebhookSent:              "Notified %s to %s (HTTP %d)",
+		WebhookRetrying:          "Webhook %s failed (%v), retrying in %v",
+		WebhookFailed:            "Webhook %s failed for %s: %v",
+		WebhookSecretMissing:     "signing secret %s is not set",
+
+		HostedReleaseCreated:      "Created %s release %s: %s",
+		HostedReleaseURLUnknown:   "Created %s release %s, its URL could not be read from the response: %v",
+		HostedReleaseFailed:       "Failed to create %s release %s: %v",
+		HostedReleasesFailed:      "Skipping hosted releases: %v",
+		HostedReleasesUnreachable: "%s API %s is unreachable, skipping hosted releases (the tags are pushed): %v",
+		HostedReleaseTokenMissing: "Skipping hosted releases: set %s to create releases on %s",
+		UnknownHostedProvider:     "unknown provider %q, use github, gitlab or gitea",
+		HostedRepositoryUnknown:   "cannot tell the repository from origin %s, set repository and api_url",
 	}
 }
 
@@ -153,18 +650,19 @@ func getChineseMessages() Messages {
 		AddLong:  "向 .rtag 文件添加新标签。如果未提供标签，将使用交互模式。",
 
 		PushShort: "推送标签到远程仓库",
-		PushLong:  "推送标签到远程仓库。使用 --all 标志推送所有标签。",
+		PushLong:  "推送标签到远程仓库。标签可以通过名称、分组（如 @backend）或通配符模式（如 'worker-*'）指定，并使用同一个时间戳发布。使用 --all 标志推送所有标签。",
 
 		ListShort: "列出所有标签",
 		ListLong:  "列出 .rtag 文件中的所有标签。",
 
 		RmShort: "删除标签",
-		RmLong:  "从 .rtag 文件中删除标签。",
+		RmLong:  "从 .rtag 文件中删除标签。标签可以通过名称、分组（如 @backend）或通配符模式指定。",
 
 		LangShort: "设置或显示当前语言",
 		LangLong:  "设置界面语言或显示当前语言设置。",
 
 		PushAllFlag: "推送所有标签",
+		FileFlag:    ".rtag 文件路径（默认: 仓库根目录下的 .rtag，或 RTAG_FILE）",
 
 		ErrorReadingRtagFile:    "错误读取 .rtag 文件: %v",
 		RtagFileEmptyOrNotExist: ".rtag 文件为空或不存在",
@@ -188,6 +686,7 @@ func getChineseMessages() Messages {
 		CreateTagFailed:         "创建 tag %s 失败: %v",
 		PushingTagsToRemote:     "推送 tags 到远程仓库...",
 		PushTagsFailed:          "推送 tags 失败: %v",
+		PushNotPossible:         "无法推送到 origin，未创建任何内容: %v",
 		PushTagsSuccess:         "成功推送所有 tags",
 		TagAlreadyExists:        "tag '%s' 已存在",
 		TagNotExist:             "tag '%s' 不存在",
@@ -201,6 +700,242 @@ func getChineseMessages() Messages {
 		LanguagePreferenceSaved: "语言偏好保存成功。",
 		LanguageChangeNote:      "注意: 语言更改将在下次命令执行时生效。",
 		InvalidLanguage:         "无效语言 '%s'。支持的语言: %s",
+
+		RollbackShort:           "将标签回滚到之前的发布",
+		RollbackLong:            "创建一个新的发布标签，指向该标签之前某次发布的提交。默认使用提交不同的最近一次发布，也可以通过 --to 指定。",
+		RollbackToFlag:          "要回滚到的发布标签（默认为上一次发布）",
+		ListReleasesFailed:      "列出发布失败: %v",
+		NoReleasesFound:         "没有找到 %s 的任何发布",
+		RollingBack:             "正在将 %s 从 %s 回滚到 %s...",
+		RollbackFailed:          "回滚失败: %v",
+		RollbackSuccess:         "成功将 %s 回滚到 %s，新标签: %s",
+		RollbackTargetIsCurrent: "发布 '%s' 就是当前发布",
+		RollbackTagExists:       "发布标签 %s 已存在，本分钟内已有其他发布，请一分钟后重试",
+		ReleaseNotFound:         "没有找到 %[2]s 的发布 '%[1]s'",
+		NoPreviousRelease:       "%s 没有可回滚的之前发布",
+
+		YankShort:            "将发布标记为已撤回",
+		YankLong:             "将发布标签标记为已撤回而不删除它。已撤回的发布会在历史中标注，并被 latest 和 rollback 跳过。",
+		YankReasonFlag:       "撤回发布的原因",
+		YankReasonRequired:   "请使用 --reason 提供原因",
+		YankFailed:           "撤回发布失败: %v",
+		YankSuccess:          "成功撤回发布: %s",
+		NotAReleaseTag:       "'%s' 不是发布标签",
+		ReleaseTagNotExist:   "发布标签 '%s' 不存在",
+		ReleaseAlreadyYanked: "发布 '%s' 已被撤回",
+		YankNotPushed:        "发布 '%s' 已在本地撤回但尚未推送，现在推送",
+		ReleaseIsYanked:      "发布 '%s' 已被撤回",
+		HistoryShort:         "显示标签的发布历史",
+		HistoryLong:          "按从新到旧显示标签的所有发布，并标注已撤回的发布。",
+		LatestShort:          "输出标签的最新发布",
+		LatestLong:           "输出该标签最新的未撤回发布标签。",
+		ReleaseHistory:       "%s 的发布历史:",
+		YankedMarker:         "[已撤回: %s]",
+
+		PromoteShort:               "将标签提升到下一个环境",
+		PromoteLong:                "在上一个环境中该标签发布的提交上创建环境标签。第一个环境使用最新发布，环境顺序在 .rtag.json 中配置且不能跳过。",
+		PromoteToFlag:              "要提升到的环境",
+		PromoteTargetRequired:      "请使用 --to 指定环境",
+		LoadConfigFailed:           "加载项目配置失败: %v",
+		PromoteFailed:              "提升失败: %v",
+		Promoting:                  "正在将 %s 提升到 %s...",
+		PromoteSuccess:             "成功将 %s 提升到 %s，新标签: %s",
+		AlreadyPromoted:            "%s 已经是 %s 中的当前版本: %s",
+		UnknownEnvironment:         "未知环境 '%s'。已配置的环境: %s",
+		EnvironmentTagsAreReleases: "无法提升到环境 '%s': 它的标签会被当作发布标签",
+		NotPromotedTo:              "%s 尚未提升到 %s",
+		OnlyYankedPromotions:       "提升到 %[2]s 的 %[1]s 发布均已撤回",
+
+		PushFloatingFlag:        "同时移动 release-latest-{tag} 浮动标签",
+		UpdateFloatingTag:       "移动浮动标签 %s 到 %s",
+		UpdateFloatingTagFailed: "移动浮动标签 %s 失败: %v",
+
+		AllGroups:          "所有分组:",
+		SelectTagsFailed:   "选择 tags 失败: %v",
+		InvalidTagName:     "tag '%s' 不能以 '@' 开头，也不能包含 ':'、','、空白或通配符",
+		InvalidTagFileLine: "%s: 无效的行 %q，应为 \"[group] 名称: tag1, tag2\" 或 \"[depends] tag -> dep1, dep2\"",
+		GroupNotExist:      "分组 '%s' 不存在",
+		GroupTagNotExist:   "分组 '%s' 包含不存在的 tag '%s'",
+		InvalidPattern:     "无效的模式 '%s': %v",
+		NoTagsMatch:        "没有 tag 匹配 '%s'",
+
+		TrainShort:          "将一组标签作为一个发布列车发布",
+		TrainLong:           "为分组中的每个标签创建发布标签，并创建一个 train-YYYYMMDDHHMM-{group} 总标签，其注释列出每个发布标签及其提交。",
+		ResolveCommitFailed: "解析 %s 的提交失败: %v",
+		StartTrain:          "开始发布列车 %s (时间戳: %s)...",
+		TrainIncomplete:     "%s 中并非所有标签都创建成功，正在删除本次运行创建的标签",
+		TrainNotPushed:      "列车 %s 未推送，正在删除其本地标签以便重新运行",
+		TrainSuccess:        "成功发布列车 %s: %s",
+
+		PushCascadeFlag: "同时推送依赖于所选标签的标签",
+		AllDependencies: "所有依赖:",
+		DependencyCycle: "依赖循环: %s",
+
+		GoModShort:                 "为嵌套的 Go 模块打标签",
+		GoModLong:                  "将嵌套的 Go 模块注册为标签，并按照 Go 工具链要求的 path/vX.Y.Z 格式创建模块标签。",
+		GoModDiscoverShort:         "将嵌套的 Go 模块注册为标签",
+		GoModDiscoverLong:          "查找嵌套的 go.mod 文件并将其目录添加到 .rtag 文件。",
+		GoModTagShort:              "创建 Go 模块版本标签",
+		GoModTagLong:               "为已注册的 Go 模块创建并推送 libs/auth/v1.4.0 这样的标签。未指定版本时使用 --bump 递增最新发布。版本必须与模块路径的 /vN 后缀一致。",
+		GoModBumpFlag:              "未指定版本时要递增的版本部分 (major、minor 或 patch)",
+		DiscoverGoModulesFailed:    "查找 Go 模块失败: %v",
+		NoGoModulesFound:           "没有找到嵌套的 Go 模块",
+		GoModuleRegistered:         "已注册 Go 模块 %s (%s)",
+		GoModuleAlreadyRegistered:  "Go 模块 %s (%s) 已注册",
+		GoModuleTagFailed:          "为 Go 模块打标签失败: %v",
+		GoModuleNoModuleLine:       "%s 中没有 module 指令",
+		InvalidGoVersion:           "无效版本 '%s'，应为 vMAJOR.MINOR.PATCH",
+		InvalidBump:                "无效的递增方式 '%s'，应为 major、minor 或 patch",
+		GoModuleMajorMismatch:      "版本 %s 与模块路径 %s 不匹配，该路径要求主版本 %d",
+		GoModuleMajorSuffixMissing: "版本 %s 要求模块路径 %s 以 /v%d 结尾",
+
+		InitDiscoverFlag:         "从 cmd/* 目录、Go 模块、Dockerfile 和已有发布标签中推荐标签",
+		DiscoverComponentsFailed: "发现标签失败: %v",
+		NoComponentsDiscovered:   "没有发现新的标签",
+		ConfirmDiscoveredTag:     "是否添加 tag '%s' (来自 %s)? (y/n): ",
+
+		InvalidTemplate:           "无效的标签模板 '%s'",
+		UnknownTemplateField:      "标签模板 '%[2]s' 中有未知占位符 '{%[1]s}'，支持: {component}、{timestamp}、{version}",
+		TemplateMissingComponent:  "标签模板 '%s' 必须包含 {component}",
+		NamingTemplateFields:      "发布标签模板 '%s' 必须包含 {component} 和 {timestamp}，且不能包含 {version}",
+		MigrateToInactiveTemplate: "'%s' 不是 %s 中的 tag_template（'%s'）：请先在其中设置，否则 history、latest、rollback 和 yank 将找不到迁移后的发布",
+		ImportShort:               "从已有的 git 标签导入标签和历史",
+		ImportLong:                "扫描匹配模板（如 '{component}-v{version}'）的已有 git 标签，将检测到的标签添加到 .rtag 文件并报告其发布历史。",
+		ImportPatternFlag:         "包含 {component} 以及可选 {timestamp} 或 {version} 占位符的标签模板，默认为 .rtag.json 中的 tag_template",
+		ImportFailed:              "导入标签失败: %v",
+		NoTagsMatchTemplate:       "没有 git 标签匹配 '%s'",
+		ImportedHistory:           "检测到 '%s' 的历史:",
+		ImportedComponent:         "  - %s: %d 次发布 (%s ... %s)",
+
+		MigrateTagsShort:         "将发布标签迁移到新的命名方案",
+		MigrateTagsLong:          "在所有匹配 --from 模板的标签的提交上创建按 --to 模板命名的标签，并保留注释和日期。使用 --delete-old 可以在本地和远程删除旧标签。",
+		MigrateFromFlag:          "现有标签的模板，例如 '{component}-v{version}'",
+		MigrateToFlag:            "新标签的模板，默认为 .rtag.json 中的 tag_template",
+		MigrateDeleteOldFlag:     "迁移后在本地和远程删除旧标签",
+		MigrateDryRunFlag:        "仅显示标签映射，不做任何更改",
+		MigrateReportFlag:        "将标签映射以 JSON 格式写入该文件",
+		MigrateFailed:            "迁移标签失败: %v",
+		MigrateDryRun:            "试运行: 将迁移 %d 个标签",
+		MigrateSuccess:           "成功迁移 %d 个标签",
+		TemplateFieldUnavailable: "无法从模板 '%[2]s' 中获得占位符 '{%[1]s}'",
+		MigrationConflict:        "标签 '%s' 和 '%s' 都将被迁移为 '%s'",
+		MigrateKeepsYank:         "[已撤回]",
+		MigrateYankFailed:        "无法将 %s 的撤回记录迁移到 %s: %v",
+		ReadTagFailed:            "读取标签 '%s' 失败",
+		DeletingOldTags:          "正在删除旧标签...",
+		DeleteOldTagsFailed:      "删除旧标签失败: %v",
+		WriteReportFailed:        "写入报告失败: %v",
+		ReportWritten:            "报告已写入 %s",
+
+		WorkspaceShort:           "在包含多个仓库的工作区上操作",
+		WorkspaceLong:            "在工作区文件（每行一个路径）列出的所有仓库上执行 list、status 和 push。",
+		WorkspaceListShort:       "列出每个仓库的标签",
+		WorkspaceStatusShort:     "显示每个仓库的分支、本地更改和最新发布",
+		WorkspacePushShort:       "使用同一个时间戳推送每个仓库的标签",
+		WorkspaceFileFlag:        "工作区文件路径",
+		WorkspaceReportFlag:      "将合并的 JSON 报告写入该文件",
+		WorkspaceFileOverride:    "工作区不能使用 --file 和 RTAG_FILE，每个仓库使用自己的 .rtag 文件",
+		ReadWorkspaceFailed:      "读取工作区失败: %v",
+		WorkspaceEmpty:           "工作区中没有仓库",
+		WorkspaceRepo:            "== %s",
+		WorkspaceRepoFailed:      "在 %s 中失败: %s",
+		WorkspaceBranchClean:     "  分支 %s，没有本地更改",
+		WorkspaceBranchDirty:     "  分支 %s，%d 处本地更改",
+		WorkspacePushRequiresAll: "请使用 --all 推送每个仓库的标签",
+		WorkspacePushSummary:     "已推送 %d/%d 个仓库 (时间戳: %s)",
+
+		TimeoutFlag:              "git 操作超过该时长则取消（如 2m，0 表示不限制）",
+		ReleaseFailed:            "发布已停止: %v",
+		Interrupted:              "已中断，正在清理本次运行创建但未推送的标签...",
+		InterruptedTagRemoved:    "  - 已删除本地标签 %s",
+		InterruptedTagRestored:   "  - 已将 %s 恢复到 %s",
+		InterruptedCleanupFailed: "  - 清理 %s 失败: %v",
+		InterruptedDuringPush:    "推送被中断，以下标签可能已存在于远程仓库: %s",
+
+		RetriesFlag:          "推送因临时错误失败时的重试次数（默认取 .rtag.json 中的 push_retries，为 3）",
+		PushAttempts:         "推送尝试：",
+		PushAttemptSucceeded: "  #%d 推送了 %d 个引用",
+		PushAttemptRetrying:  "  #%d 失败，%[3]s 后重试 %[2]d 个引用：%[4]v",
+		PushAttemptFailed:    "  #%d 失败：%v",
+
+		TagFileLocked: "另一个 rtag 进程正在更新 %s，如果没有其他进程在运行，请删除 %s.lock",
+
+		LockShort:       "查看或解除远程仓库上的发布锁",
+		LockStatusShort: "列出正在发布的组件及持有锁的人",
+		LockBreakShort:  "解除组件的发布锁，例如在发布崩溃之后",
+		PushLockFlag:    "发布期间在远程仓库上锁定组件（也可在 .rtag.json 中设置 release_lock）",
+		AcquiringLocks:  "正在远程仓库上锁定 %s...",
+		ComponentLocked: "%s 正由 %s 自 %s 起发布（锁在 %s 过期），请稍后重试或运行 'rtag lock break %s'",
+		LockFailed:      "锁定发布失败: %v",
+		UnlockFailed:    "释放锁失败，锁会自行过期: %v",
+		InvalidLockTTL:  ".rtag.json 中的 release_lock_ttl %q 无效",
+		ReadLocksFailed: "读取发布锁失败: %v",
+		NoLocks:         "没有被锁定的组件",
+		LockHeld:        "  - %s: %s，自 %s 起，%s 过期",
+		LockExpired:     "  - %s: %s，自 %s 起，已于 %s 过期",
+		LockBroken:      "已解除 %s 的发布锁",
+		NotLocked:       "%s 未被锁定",
+		BreakLockFailed: "解除 %s 的发布锁失败: %v",
+
+		PolicyShort:          "评估 .rtag.json 中的发布策略",
+		PolicyCheckShort:     "检查标签的发布策略而不发布",
+		PolicyCheckFailed:    "检查发布策略失败: %v",
+		PolicyInvalidSetting: "无效的策略设置 %s: %q",
+		PolicyViolated:       "发布策略不允许本次发布：",
+		PolicyPassed:         "发布策略允许发布 %s",
+		PolicyBranch:         "  - 分支 %s 不允许发布，允许的分支: %s",
+		PolicyCleanTree:      "  - 工作区有 %s 处未提交的修改，必须保持干净",
+		PolicyMinInterval:    "  - %s 在 %s 前刚发布过（%s），两次发布之间至少需要间隔 %s",
+		PolicyApprovals:      "  - 发布的提交有 %s 个 Approved-by 尾注，需要 %s 个",
+		PolicyReleasers:      "  - %s 无权发布，允许的发布者: %s",
+		PolicyDays:           "  - %s 不允许发布，允许的日期: %s",
+		PolicyHours:          "  - %s 不允许发布，允许的时段: %s",
+
+		FreezeShort:          "显示 .rtag.json 中的发布冻结期",
+		FreezeListShort:      "列出当前和即将到来的发布冻结期",
+		FreezeDaysFlag:       "显示未来多少天内的冻结期",
+		OverrideFreezeFlag:   "在冻结期内发布，需要 --reason",
+		FreezeReasonFlag:     "覆盖冻结期的原因，记录在标签注释中",
+		FreezeCheckFailed:    "检查发布冻结期失败: %v",
+		ReleaseFrozen:        "发布已冻结:",
+		FreezeWindowLine:     "  - %s: %s - %s (%s)",
+		FreezeActive:         "[生效中]",
+		FreezeAllComponents:  "所有组件",
+		FreezeOverrideHint:   "使用 --override-freeze --reason \"...\" 强制发布",
+		FreezeReasonRequired: "--override-freeze 需要提供 --reason",
+		FreezeOverridden:     "覆盖冻结期 %s: %s",
+		NoFreezes:            "未来 %d 天内没有发布冻结期",
+		FreezeListHeader:     "未来 %d 天内的发布冻结期:",
+
+		PushReportFlag:       "将发布的 JSON 报告（包括钩子输出）写入此文件",
+		TrainReportFlag:      "将发布列车的 JSON 报告（包括钩子输出、托管发布和 Webhook 投递结果）写入此文件",
+		RunningHook:          "运行 %s 钩子: %s",
+		RunningComponentHook: "运行 %s 钩子: %s (%s)",
+		HookFailed:           "钩子 %s 失败: %v",
+		InvalidHookTimeout:   "无效的钩子超时 %q",
+		ReleaseAbortedByHook: "发布被 pre_release 钩子中止，未创建任何标签",
+
+		NotifyShort:              "管理发布后通知的 webhook",
+		NotifyTestShort:          "向配置的 webhook 发送测试负载",
+		NotifyURLFlag:            "将测试负载发送到此 URL，而不是配置的 webhook",
+		NotifyAllFlag:            "将测试负载发送到所有配置的 webhook，包括生产环境的",
+		NoWebhooks:               "未在 .rtag.json 中配置 webhook",
+		NotifyTestTargetRequired: "请使用 --url 将测试负载发送到单个端点，或使用 --all 发送到已配置的 %d 个 webhook",
+		NotifyWebhookFlag:        "--url 使用其 body、headers、content type 和签名密钥的已配置 webhook 编号",
+		NotifyWebhookNotFound:    "webhook %d 不存在，共配置了 %d 个",
+		WebhookSent:              "已将 %s 通知到 %s (HTTP %d)",
+		WebhookRetrying:          "Webhook %s 失败 (%v)，%v 后重试",
+		WebhookFailed:            "Webhook %s 通知 %s 失败: %v",
+		WebhookSecretMissing:     "签名密钥 %s 未设置",
+
+		HostedReleaseCreated:      "已创建 %s 发布 %s: %s",
+		HostedReleaseURLUnknown:   "已创建 %s 发布 %s，无法从响应中读取其 URL: %v",
+		HostedReleaseFailed:       "创建 %s 发布 %s 失败: %v",
+		HostedReleasesFailed:      "跳过托管平台发布: %v",
+		HostedReleasesUnreachable: "%s API %s 无法访问，跳过托管平台发布（标签已推送）: %v",
+		HostedReleaseTokenMissing: "跳过托管平台发布: 设置 %s 以在 %s 上创建发布",
+		UnknownHostedProvider:     "未知的托管平台 %q，请使用 github、gitlab 或 gitea",
+		HostedRepositoryUnknown:   "无法从 origin %s 确定仓库，请设置 repository 和 api_url",
 	}
 }
 
@@ -217,18 +952,19 @@ func getFrenchMessages() Messages {
 		AddLong:  "Ajouter un nouveau tag au fichier .rtag. Si aucun tag n'est fourni, le mode interactif sera utilisé.",
 
 		PushShort: "Pousser les tags vers le dépôt distant",
-		PushLong:  "Pousser les tags vers le dépôt distant. Utilisez le flag --all pour pousser tous les tags.",
+		PushLong:  "Pousser les tags vers le dépôt distant. Les tags peuvent être donnés par nom, par groupe comme @backend ou par motif glob comme 'worker-*', et sont publiés avec un horodatage commun. Utilisez le flag --all pour pousser tous les tags.",
 
 		ListShort: "Lister tous les tags",
 		ListLong:  "Lister tous les tags du fichier .rtag.",
 
 		RmShort: "Supprimer un tag",
-		RmLong:  "Supprimer un tag du fichier .rtag.",
+		RmLong:  "Supprimer des tags du fichier .rtag. Les tags peuvent être donnés par nom, par groupe comme @backend ou par motif glob.",
 
 		LangShort: "Définir ou afficher la langue actuelle",
 		LangLong:  "Définir la langue de l'interface ou afficher les paramètres de langue actuels.",
 
 		PushAllFlag: "Pousser tous les tags",
+		FileFlag:    "Chemin du fichier .rtag (par défaut: .rtag à la racine du dépôt, ou RTAG_FILE)",
 
 		ErrorReadingRtagFile:    "Erreur lors de la lecture du fichier .rtag: %v",
 		RtagFileEmptyOrNotExist: "Le fichier .rtag est vide ou n'existe pas",
@@ -252,6 +988,7 @@ func getFrenchMessages() Messages {
 		CreateTagFailed:         "Échec de la création du tag %s: %v",
 		PushingTagsToRemote:     "Poussée des tags vers le dépôt distant...",
 		PushTagsFailed:          "Échec de la poussée des tags: %v",
+		PushNotPossible:         "Impossible de pousser vers origin, rien n'a été créé: %v",
 		PushTagsSuccess:         "Tous les tags ont été poussés avec succès",
 		TagAlreadyExists:        "le tag '%s' existe déjà",
 		TagNotExist:             "le tag '%s' n'existe pas",
@@ -265,6 +1002,242 @@ func getFrenchMessages() Messages {
 		LanguagePreferenceSaved: "Préférence de langue sauvegardée avec succès.",
 		LanguageChangeNote:      "Note: Le changement de langue prendra effet lors de la prochaine exécution de commande.",
 		InvalidLanguage:         "Langue invalide '%s'. Langues supportées: %s",
+
+		RollbackShort:           "Revenir à une version précédente d'un tag",
+		RollbackLong:            "Créer un nouveau tag de version pointant vers le commit d'une version précédente du tag. Par défaut, la version la plus récente avec un commit différent est utilisée, ou choisissez-en une avec --to.",
+		RollbackToFlag:          "Tag de version vers lequel revenir (par défaut la version précédente)",
+		ListReleasesFailed:      "Échec de la liste des versions: %v",
+		NoReleasesFound:         "Aucune version trouvée pour %s",
+		RollingBack:             "Retour de %s de %s vers %s...",
+		RollbackFailed:          "Échec du retour en arrière: %v",
+		RollbackSuccess:         "%s ramené avec succès à %s sous %s",
+		RollbackTargetIsCurrent: "la version '%s' est la version actuelle",
+		RollbackTagExists:       "le tag de release %s existe déjà, une autre release a été faite cette minute, réessayez dans une minute",
+		ReleaseNotFound:         "version '%s' introuvable pour %s",
+		NoPreviousRelease:       "aucune version précédente de %s vers laquelle revenir",
+
+		YankShort:            "Marquer une version comme retirée",
+		YankLong:             "Marquer un tag de version comme retiré sans le supprimer. Les versions retirées sont annotées dans l'historique et ignorées par latest et rollback.",
+		YankReasonFlag:       "Raison du retrait de la version",
+		YankReasonRequired:   "Veuillez fournir une raison avec --reason",
+		YankFailed:           "Échec du retrait de la version: %v",
+		YankSuccess:          "Version retirée avec succès: %s",
+		NotAReleaseTag:       "'%s' n'est pas un tag de version",
+		ReleaseTagNotExist:   "le tag de version '%s' n'existe pas",
+		ReleaseAlreadyYanked: "la version '%s' est déjà retirée",
+		YankNotPushed:        "la version '%s' a été retirée localement mais le retrait n'a pas été poussé, envoi en cours",
+		ReleaseIsYanked:      "la version '%s' est retirée",
+		HistoryShort:         "Afficher l'historique des versions d'un tag",
+		HistoryLong:          "Afficher toutes les versions d'un tag de la plus récente à la plus ancienne, en annotant les versions retirées.",
+		LatestShort:          "Afficher la dernière version d'un tag",
+		LatestLong:           "Afficher le tag de la version la plus récente non retirée d'un tag.",
+		ReleaseHistory:       "Historique des versions de %s:",
+		YankedMarker:         "[retirée: %s]",
+
+		PromoteShort:               "Promouvoir un tag vers l'environnement suivant",
+		PromoteLong:                "Créer un tag d'environnement sur le commit de la version du tag dans l'environnement précédent. Le premier environnement utilise la dernière version, les environnements sont configurés dans .rtag.json et ne peuvent pas être sautés.",
+		PromoteToFlag:              "Environnement vers lequel promouvoir",
+		PromoteTargetRequired:      "Veuillez spécifier l'environnement avec --to",
+		LoadConfigFailed:           "Échec du chargement de la configuration du projet: %v",
+		PromoteFailed:              "Échec de la promotion: %v",
+		Promoting:                  "Promotion de %s vers %s...",
+		PromoteSuccess:             "%s promu avec succès vers %s sous %s",
+		AlreadyPromoted:            "%s est déjà actuel dans %s sous %s",
+		UnknownEnvironment:         "environnement inconnu '%s'. Environnements configurés: %s",
+		EnvironmentTagsAreReleases: "impossible de promouvoir vers l'environnement '%s': ses tags seraient pris pour des tags de release",
+		NotPromotedTo:              "%s n'a pas encore été promu vers %s",
+		OnlyYankedPromotions:       "toutes les versions de %s promues vers %s ont été retirées",
+
+		PushFloatingFlag:        "Déplacer aussi les tags flottants release-latest-{tag}",
+		UpdateFloatingTag:       "Déplacement du tag flottant %s vers %s",
+		UpdateFloatingTagFailed: "Échec du déplacement du tag flottant %s: %v",
+
+		AllGroups:          "Tous les groupes:",
+		SelectTagsFailed:   "Échec de la sélection des tags: %v",
+		InvalidTagName:     "le tag '%s' ne doit pas commencer par '@' ni contenir ':', ',', d'espaces ou de caractères glob",
+		InvalidTagFileLine: "%s : ligne invalide %q, attendu \"[group] nom: tag1, tag2\" ou \"[depends] tag -> dep1, dep2\"",
+		GroupNotExist:      "le groupe '%s' n'existe pas",
+		GroupTagNotExist:   "le groupe '%s' contient le tag '%s' qui n'existe pas",
+		InvalidPattern:     "motif invalide '%s': %v",
+		NoTagsMatch:        "aucun tag ne correspond à '%s'",
+
+		TrainShort:          "Publier un groupe de tags comme un train de versions",
+		TrainLong:           "Créer des tags de version pour chaque tag d'un groupe ainsi qu'un tag parapluie train-YYYYMMDDHHMM-{group} dont l'annotation liste chaque tag de version et son commit.",
+		ResolveCommitFailed: "Échec de la résolution du commit de %s: %v",
+		StartTrain:          "Début du train de versions %s (horodatage: %s)...",
+		TrainIncomplete:     "Tous les tags de %s n'ont pas pu être créés, suppression des tags créés par cette exécution",
+		TrainNotPushed:      "Le train %s n'a pas été poussé, suppression de ses tags locaux pour pouvoir le relancer",
+		TrainSuccess:        "Train %s publié avec succès sous %s",
+
+		PushCascadeFlag: "Pousser aussi les tags qui dépendent des tags donnés",
+		AllDependencies: "Toutes les dépendances:",
+		DependencyCycle: "cycle de dépendances: %s",
+
+		GoModShort:                 "Taguer les modules Go imbriqués",
+		GoModLong:                  "Enregistrer les modules Go imbriqués comme tags et créer des tags de module au format path/vX.Y.Z attendu par la chaîne d'outils Go.",
+		GoModDiscoverShort:         "Enregistrer les modules Go imbriqués comme tags",
+		GoModDiscoverLong:          "Trouver les fichiers go.mod imbriqués et ajouter leurs répertoires au fichier .rtag.",
+		GoModTagShort:              "Créer un tag de version de module Go",
+		GoModTagLong:               "Créer et pousser un tag tel que libs/auth/v1.4.0 pour un module Go enregistré. Sans version, la dernière version est incrémentée avec --bump. Les versions doivent correspondre au suffixe /vN du chemin du module.",
+		GoModBumpFlag:              "Partie de la version à incrémenter sans version donnée (major, minor ou patch)",
+		DiscoverGoModulesFailed:    "Échec de la découverte des modules Go: %v",
+		NoGoModulesFound:           "Aucun module Go imbriqué trouvé",
+		GoModuleRegistered:         "Module Go %s enregistré (%s)",
+		GoModuleAlreadyRegistered:  "Le module Go %s (%s) est déjà enregistré",
+		GoModuleTagFailed:          "Échec du tag du module Go: %v",
+		GoModuleNoModuleLine:       "%s n'a pas de directive module",
+		InvalidGoVersion:           "version invalide '%s', vMAJOR.MINOR.PATCH attendu",
+		InvalidBump:                "incrément invalide '%s', major, minor ou patch attendu",
+		GoModuleMajorMismatch:      "la version %s ne correspond pas au chemin de module %s, qui exige la version majeure %d",
+		GoModuleMajorSuffixMissing: "la version %s exige que le chemin de module %s se termine par /v%d",
+
+		InitDiscoverFlag:         "Proposer des tags à partir des répertoires cmd/*, des modules Go, des Dockerfiles et des tags de version existants",
+		DiscoverComponentsFailed: "Échec de la découverte des tags: %v",
+		NoComponentsDiscovered:   "Aucun nouveau tag découvert",
+		ConfirmDiscoveredTag:     "Ajouter le tag '%s' (depuis %s)? (y/n): ",
+
+		InvalidTemplate:           "modèle de tag invalide '%s'",
+		UnknownTemplateField:      "espace réservé inconnu '{%s}' dans le modèle de tag '%s', pris en charge: {component}, {timestamp}, {version}",
+		TemplateMissingComponent:  "le modèle de tag '%s' doit contenir {component}",
+		NamingTemplateFields:      "le modèle de tag de release '%s' doit contenir {component} et {timestamp}, et pas {version}",
+		MigrateToInactiveTemplate: "'%s' n'est pas le tag_template de %s ('%s'): définissez-le d'abord, sinon history, latest, rollback et yank ne trouveraient pas les releases migrées",
+		ImportShort:               "Importer les tags et l'historique depuis les tags git existants",
+		ImportLong:                "Analyser les tags git existants correspondant à un modèle comme '{component}-v{version}', ajouter les tags détectés au fichier .rtag et afficher leur historique de versions.",
+		ImportPatternFlag:         "Modèle de tag avec {component} et les espaces réservés optionnels {timestamp} ou {version}, tag_template de .rtag.json par défaut",
+		ImportFailed:              "Échec de l'import des tags: %v",
+		NoTagsMatchTemplate:       "Aucun tag git ne correspond à '%s'",
+		ImportedHistory:           "Historique détecté pour '%s':",
+		ImportedComponent:         "  - %s: %d versions (%s ... %s)",
+
+		MigrateTagsShort:         "Migrer les tags de version vers un nouveau schéma de nommage",
+		MigrateTagsLong:          "Créer des tags nommés selon le modèle --to sur les commits de tous les tags correspondant au modèle --from, en préservant annotations et dates. Les anciens tags peuvent être supprimés localement et à distance avec --delete-old.",
+		MigrateFromFlag:          "Modèle des tags existants, par ex. '{component}-v{version}'",
+		MigrateToFlag:            "Modèle des nouveaux tags, tag_template de .rtag.json par défaut",
+		MigrateDeleteOldFlag:     "Supprimer les anciens tags localement et à distance après la migration",
+		MigrateDryRunFlag:        "Afficher uniquement la correspondance des tags sans rien modifier",
+		MigrateReportFlag:        "Écrire la correspondance des tags en JSON dans ce fichier",
+		MigrateFailed:            "Échec de la migration des tags: %v",
+		MigrateDryRun:            "Simulation: %d tags seraient migrés",
+		MigrateSuccess:           "%d tags migrés avec succès",
+		TemplateFieldUnavailable: "l'espace réservé '{%s}' n'est pas disponible dans le modèle '%s'",
+		MigrationConflict:        "les tags '%s' et '%s' seraient tous deux migrés vers '%s'",
+		MigrateKeepsYank:         "[retirée]",
+		MigrateYankFailed:        "Impossible de reporter le retrait de %s sur %s : %v",
+		ReadTagFailed:            "échec de la lecture du tag '%s'",
+		DeletingOldTags:          "Suppression des anciens tags...",
+		DeleteOldTagsFailed:      "Échec de la suppression des anciens tags: %v",
+		WriteReportFailed:        "Échec de l'écriture du rapport: %v",
+		ReportWritten:            "Rapport écrit dans %s",
+
+		WorkspaceShort:           "Opérer sur un espace de travail de plusieurs dépôts",
+		WorkspaceLong:            "Exécuter list, status et push sur tous les dépôts listés dans le fichier d'espace de travail, un chemin par ligne.",
+		WorkspaceListShort:       "Lister les tags de chaque dépôt",
+		WorkspaceStatusShort:     "Afficher la branche, les modifications locales et les dernières versions de chaque dépôt",
+		WorkspacePushShort:       "Pousser les tags de chaque dépôt avec un horodatage commun",
+		WorkspaceFileFlag:        "Chemin du fichier d'espace de travail",
+		WorkspaceReportFlag:      "Écrire un rapport JSON combiné dans ce fichier",
+		WorkspaceFileOverride:    "--file et RTAG_FILE ne peuvent pas être utilisés avec les espaces de travail, chaque dépôt utilise son propre fichier .rtag",
+		ReadWorkspaceFailed:      "Échec de la lecture de l'espace de travail: %v",
+		WorkspaceEmpty:           "L'espace de travail ne contient aucun dépôt",
+		WorkspaceRepo:            "== %s",
+		WorkspaceRepoFailed:      "Échec dans %s: %s",
+		WorkspaceBranchClean:     "  branche %s, aucune modification locale",
+		WorkspaceBranchDirty:     "  branche %s, %d modifications locales",
+		WorkspacePushRequiresAll: "Veuillez utiliser --all pour pousser les tags de chaque dépôt",
+		WorkspacePushSummary:     "%d dépôts poussés sur %d (horodatage: %s)",
+
+		TimeoutFlag:              "Annuler une opération git qui dure plus longtemps (ex. 2m, 0 pour aucune limite)",
+		ReleaseFailed:            "Publication arrêtée : %v",
+		Interrupted:              "Interrompu, nettoyage des tags créés par cette exécution et non poussés...",
+		InterruptedTagRemoved:    "  - tag local %s supprimé",
+		InterruptedTagRestored:   "  - %s restauré sur %s",
+		InterruptedCleanupFailed: "  - échec du nettoyage de %s : %v",
+		InterruptedDuringPush:    "La poussée a été interrompue, ces tags existent peut-être déjà sur le dépôt distant : %s",
+
+		RetriesFlag:          "Nombre de nouvelles tentatives d'un push échoué sur une erreur passagère (par défaut push_retries dans .rtag.json, 3)",
+		PushAttempts:         "Tentatives de push :",
+		PushAttemptSucceeded: "  #%d : %d référence(s) poussée(s)",
+		PushAttemptRetrying:  "  #%d a échoué, nouvelle tentative pour %d référence(s) dans %s : %v",
+		PushAttemptFailed:    "  #%d a échoué : %v",
+
+		TagFileLocked: "%s est en cours de modification par un autre processus rtag, supprimez %s.lock si aucun n'est en cours",
+
+		LockShort:       "Afficher ou lever les verrous de publication sur le dépôt distant",
+		LockStatusShort: "Lister les composants en cours de publication et le détenteur de leur verrou",
+		LockBreakShort:  "Supprimer le verrou de publication de composants, par exemple après une publication interrompue",
+		PushLockFlag:    "Verrouiller les composants sur le dépôt distant pendant leur publication (aussi release_lock dans .rtag.json)",
+		AcquiringLocks:  "Verrouillage de %s sur le dépôt distant...",
+		ComponentLocked: "%s est en cours de publication par %s depuis %s (le verrou expire %s), réessayez plus tard ou lancez 'rtag lock break %s'",
+		LockFailed:      "Échec du verrouillage de la publication : %v",
+		UnlockFailed:    "Échec de la libération des verrous, ils expireront d'eux-mêmes : %v",
+		InvalidLockTTL:  "release_lock_ttl %q invalide dans .rtag.json",
+		ReadLocksFailed: "Échec de la lecture des verrous de publication : %v",
+		NoLocks:         "Aucun composant n'est verrouillé",
+		LockHeld:        "  - %s : %s, depuis %s, expire %s",
+		LockExpired:     "  - %s : %s, depuis %s, expiré %s",
+		LockBroken:      "Verrou de publication de %s supprimé",
+		NotLocked:       "%s n'est pas verrouillé",
+		BreakLockFailed: "Échec de la suppression du verrou de publication de %s : %v",
+
+		PolicyShort:          "Évaluer la politique de publication de .rtag.json",
+		PolicyCheckShort:     "Vérifier la politique de publication des tags sans les publier",
+		PolicyCheckFailed:    "Échec de la vérification de la politique de publication : %v",
+		PolicyInvalidSetting: "paramètre de politique %s invalide : %q",
+		PolicyViolated:       "La politique de publication n'autorise pas cette publication :",
+		PolicyPassed:         "La politique de publication autorise la publication de %s",
+		PolicyBranch:         "  - la branche %s n'est pas autorisée pour les publications, autorisées : %s",
+		PolicyCleanTree:      "  - l'arbre de travail a %s modification(s) non commitée(s), il doit être propre",
+		PolicyMinInterval:    "  - %s a été publié il y a %s (%s), au moins %s doivent s'écouler entre deux publications",
+		PolicyApprovals:      "  - le commit publié a %s trailer(s) Approved-by, %s requis",
+		PolicyReleasers:      "  - %s n'est pas autorisé à publier, autorisés : %s",
+		PolicyDays:           "  - les publications ne sont pas autorisées le %s, jours autorisés : %s",
+		PolicyHours:          "  - les publications ne sont pas autorisées à %s, heures autorisées : %s",
+
+		FreezeShort:          "Afficher les gels de release de .rtag.json",
+		FreezeListShort:      "Lister les gels de release en cours et à venir",
+		FreezeDaysFlag:       "Nombre de jours à afficher",
+		OverrideFreezeFlag:   "Publier pendant un gel, nécessite --reason",
+		FreezeReasonFlag:     "Raison du contournement du gel, enregistrée dans l'annotation du tag",
+		FreezeCheckFailed:    "Échec de la vérification des gels de release : %v",
+		ReleaseFrozen:        "Les releases sont gelées :",
+		FreezeWindowLine:     "  - %s : %s - %s (%s)",
+		FreezeActive:         "[en cours]",
+		FreezeAllComponents:  "tous les composants",
+		FreezeOverrideHint:   "Utilisez --override-freeze --reason \"...\" pour publier malgré tout",
+		FreezeReasonRequired: "--override-freeze nécessite une --reason",
+		FreezeOverridden:     "Contournement du gel %s : %s",
+		NoFreezes:            "Aucun gel de release dans les %d prochains jours",
+		FreezeListHeader:     "Gels de release dans les %d prochains jours :",
+
+		PushReportFlag:       "Écrire un rapport JSON de la release, sortie des hooks comprise, dans ce fichier",
+		TrainReportFlag:      "Écrire un rapport JSON du train, sortie des hooks, releases hébergées et envois de webhooks compris, dans ce fichier",
+		RunningHook:          "Exécution du hook %s : %s",
+		RunningComponentHook: "Exécution du hook %s : %s (%s)",
+		HookFailed:           "Le hook %s a échoué : %v",
+		InvalidHookTimeout:   "délai du hook invalide %q",
+		ReleaseAbortedByHook: "Release annulée par un hook pre_release, aucun tag n'a été créé",
+
+		NotifyShort:              "Gérer les webhooks notifiés après les releases",
+		NotifyTestShort:          "Envoyer une charge utile de test aux webhooks configurés",
+		NotifyURLFlag:            "Envoyer la charge utile de test à cette URL au lieu des webhooks configurés",
+		NotifyAllFlag:            "Envoyer la charge utile de test à tous les webhooks configurés, y compris ceux de production",
+		NoWebhooks:               "Aucun webhook configuré dans .rtag.json",
+		NotifyTestTargetRequired: "Utilisez --url pour envoyer la charge utile de test à un seul point d'accès, ou --all pour l'envoyer aux %d webhooks configurés",
+		NotifyWebhookFlag:        "Numéro du webhook configuré dont --url reprend le corps, les en-têtes, le type de contenu et le secret",
+		NotifyWebhookNotFound:    "Le webhook %d n'existe pas, %d sont configurés",
+		WebhookSent:              "%s notifié à %s (HTTP %d)",
+		WebhookRetrying:          "Le webhook %s a échoué (%v), nouvel essai dans %v",
+		WebhookFailed:            "Le webhook %s a échoué pour %s : %v",
+		WebhookSecretMissing:     "le secret de signature %s n'est pas défini",
+
+		HostedReleaseCreated:      "Release %s %s créée : %s",
+		HostedReleaseURLUnknown:   "Release %s %s créée, son URL n'a pas pu être lue dans la réponse : %v",
+		HostedReleaseFailed:       "Échec de la création de la release %s %s : %v",
+		HostedReleasesFailed:      "Releases hébergées ignorées : %v",
+		HostedReleasesUnreachable: "L'API %s %s est injoignable, releases hébergées ignorées (les tags sont poussés) : %v",
+		HostedReleaseTokenMissing: "Releases hébergées ignorées : définissez %s pour créer des releases sur %s",
+		UnknownHostedProvider:     "fournisseur inconnu %q, utilisez github, gitlab ou gitea",
+		HostedRepositoryUnknown:   "impossible de déduire le dépôt de l'origin %s, définissez repository et api_url",
 	}
 }
 
@@ -281,18 +1254,19 @@ func getRussianMessages() Messages {
 		AddLong:  "Добавить новый тег в файл .rtag. Если тег не указан, будет использован интерактивный режим.",
 
 		PushShort: "Отправить теги в удаленный репозиторий",
-		PushLong:  "Отправить теги в удаленный репозиторий. Используйте флаг --all для отправки всех тегов.",
+		PushLong:  "Отправить теги в удаленный репозиторий. Теги можно указать по имени, группой, например @backend, или glob-шаблоном, например 'worker-*', и они публикуются с общей временной меткой. Используйте флаг --all для отправки всех тегов.",
 
 		ListShort: "Показать все теги",
 		ListLong:  "Показать все теги из файла .rtag.",
 
 		RmShort: "Удалить тег",
-		RmLong:  "Удалить тег из файла .rtag.",
+		RmLong:  "Удалить теги из файла .rtag. Теги можно указать по имени, группой, например @backend, или glob-шаблоном.",
 
 		LangShort: "Установить или показать текущий язык",
 		LangLong:  "Установить язык интерфейса или показать текущие настройки языка.",
 
 		PushAllFlag: "Отправить все теги",
+		FileFlag:    "Путь к файлу .rtag (по умолчанию: .rtag в корне репозитория или RTAG_FILE)",
 
 		ErrorReadingRtagFile:    "Ошибка чтения файла .rtag: %v",
 		RtagFileEmptyOrNotExist: "Файл .rtag пуст или не существует",
@@ -316,6 +1290,7 @@ func getRussianMessages() Messages {
 		CreateTagFailed:         "Не удалось создать тег %s: %v",
 		PushingTagsToRemote:     "Отправка тегов в удаленный репозиторий...",
 		PushTagsFailed:          "Не удалось отправить теги: %v",
+		PushNotPossible:         "Невозможно отправить в origin, ничего не создано: %v",
 		PushTagsSuccess:         "Все теги успешно отправлены",
 		TagAlreadyExists:        "тег '%s' уже существует",
 		TagNotExist:             "тег '%s' не существует",
@@ -329,5 +1304,241 @@ func getRussianMessages() Messages {
 		LanguagePreferenceSaved: "Языковые предпочтения успешно сохранены.",
 		LanguageChangeNote:      "Примечание: Изменение языка вступит в силу при следующем выполнении команды.",
 		InvalidLanguage:         "Недопустимый язык '%s'. Поддерживаемые языки: %s",
+
+		RollbackShort:           "Откатить тег к предыдущему релизу",
+		RollbackLong:            "Создать новый тег релиза, указывающий на коммит предыдущего релиза тега. По умолчанию используется последний релиз с другим коммитом, либо выберите его с помощью --to.",
+		RollbackToFlag:          "Тег релиза для отката (по умолчанию предыдущий релиз)",
+		ListReleasesFailed:      "Не удалось получить список релизов: %v",
+		NoReleasesFound:         "Релизы для %s не найдены",
+		RollingBack:             "Откат %s с %s на %s...",
+		RollbackFailed:          "Не удалось выполнить откат: %v",
+		RollbackSuccess:         "%s успешно откачен на %s как %s",
+		RollbackTargetIsCurrent: "релиз '%s' является текущим",
+		RollbackTagExists:       "тег релиза %s уже существует, в эту минуту уже был другой релиз, повторите через минуту",
+		ReleaseNotFound:         "релиз '%s' не найден для %s",
+		NoPreviousRelease:       "нет предыдущего релиза %s для отката",
+
+		YankShort:            "Пометить релиз как отозванный",
+		YankLong:             "Пометить тег релиза как отозванный, не удаляя его. Отозванные релизы отмечаются в истории и пропускаются командами latest и rollback.",
+		YankReasonFlag:       "Причина отзыва релиза",
+		YankReasonRequired:   "Пожалуйста, укажите причину с помощью --reason",
+		YankFailed:           "Не удалось отозвать релиз: %v",
+		YankSuccess:          "Релиз успешно отозван: %s",
+		NotAReleaseTag:       "'%s' не является тегом релиза",
+		ReleaseTagNotExist:   "тег релиза '%s' не существует",
+		ReleaseAlreadyYanked: "релиз '%s' уже отозван",
+		YankNotPushed:        "релиз '%s' отозван локально, но отзыв не был отправлен, отправляем сейчас",
+		ReleaseIsYanked:      "релиз '%s' отозван",
+		HistoryShort:         "Показать историю релизов тега",
+		HistoryLong:          "Показать все релизы тега от новых к старым, отмечая отозванные релизы.",
+		LatestShort:          "Вывести последний релиз тега",
+		LatestLong:           "Вывести тег последнего неотозванного релиза тега.",
+		ReleaseHistory:       "История релизов %s:",
+		YankedMarker:         "[отозван: %s]",
+
+		PromoteShort:               "Продвинуть тег в следующее окружение",
+		PromoteLong:                "Создать тег окружения на коммите релиза тега в предыдущем окружении. Первое окружение использует последний релиз, окружения настраиваются в .rtag.json и не могут быть пропущены.",
+		PromoteToFlag:              "Окружение для продвижения",
+		PromoteTargetRequired:      "Пожалуйста, укажите окружение с помощью --to",
+		LoadConfigFailed:           "Не удалось загрузить конфигурацию проекта: %v",
+		PromoteFailed:              "Не удалось выполнить продвижение: %v",
+		Promoting:                  "Продвижение %s в %s...",
+		PromoteSuccess:             "%s успешно продвинут в %s как %s",
+		AlreadyPromoted:            "%s уже является текущим в %s как %s",
+		UnknownEnvironment:         "неизвестное окружение '%s'. Настроенные окружения: %s",
+		EnvironmentTagsAreReleases: "нельзя продвигать в окружение '%s': его теги будут приняты за теги релизов",
+		NotPromotedTo:              "%s ещё не продвинут в %s",
+		OnlyYankedPromotions:       "все релизы %s, продвинутые в %s, отозваны",
+
+		PushFloatingFlag:        "Также переместить плавающие теги release-latest-{tag}",
+		UpdateFloatingTag:       "Перемещение плавающего тега %s на %s",
+		UpdateFloatingTagFailed: "Не удалось переместить плавающий тег %s: %v",
+
+		AllGroups:          "Все группы:",
+		SelectTagsFailed:   "Не удалось выбрать теги: %v",
+		InvalidTagName:     "тег '%s' не должен начинаться с '@' или содержать ':', ',', пробелы или glob-символы",
+		InvalidTagFileLine: "%s: недопустимая строка %q, ожидается \"[group] имя: tag1, tag2\" или \"[depends] tag -> dep1, dep2\"",
+		GroupNotExist:      "группа '%s' не существует",
+		GroupTagNotExist:   "группа '%s' содержит несуществующий тег '%s'",
+		InvalidPattern:     "недопустимый шаблон '%s': %v",
+		NoTagsMatch:        "нет тегов, соответствующих '%s'",
+
+		TrainShort:          "Выпустить группу тегов как один релизный поезд",
+		TrainLong:           "Создать теги релизов для каждого тега группы, а также общий тег train-YYYYMMDDHHMM-{group}, аннотация которого перечисляет каждый тег релиза и коммит.",
+		ResolveCommitFailed: "Не удалось определить коммит %s: %v",
+		StartTrain:          "Запуск релизного поезда %s (временная метка: %s)...",
+		TrainIncomplete:     "Не все теги %s удалось создать, удаляем теги, созданные этим запуском",
+		TrainNotPushed:      "Поезд %s не отправлен, удаляем его локальные теги, чтобы его можно было запустить снова",
+		TrainSuccess:        "Поезд %s успешно выпущен как %s",
+
+		PushCascadeFlag: "Также отправить теги, зависящие от указанных тегов",
+		AllDependencies: "Все зависимости:",
+		DependencyCycle: "цикл зависимостей: %s",
+
+		GoModShort:                 "Тегировать вложенные модули Go",
+		GoModLong:                  "Зарегистрировать вложенные модули Go как теги и создавать теги модулей в формате path/vX.Y.Z, ожидаемом инструментарием Go.",
+		GoModDiscoverShort:         "Зарегистрировать вложенные модули Go как теги",
+		GoModDiscoverLong:          "Найти вложенные файлы go.mod и добавить их каталоги в файл .rtag.",
+		GoModTagShort:              "Создать тег версии модуля Go",
+		GoModTagLong:               "Создать и отправить тег вида libs/auth/v1.4.0 для зарегистрированного модуля Go. Без версии последний релиз увеличивается с помощью --bump. Версии должны соответствовать суффиксу /vN пути модуля.",
+		GoModBumpFlag:              "Часть версии для увеличения, если версия не указана (major, minor или patch)",
+		DiscoverGoModulesFailed:    "Не удалось найти модули Go: %v",
+		NoGoModulesFound:           "Вложенные модули Go не найдены",
+		GoModuleRegistered:         "Зарегистрирован модуль Go %s (%s)",
+		GoModuleAlreadyRegistered:  "Модуль Go %s (%s) уже зарегистрирован",
+		GoModuleTagFailed:          "Не удалось создать тег модуля Go: %v",
+		GoModuleNoModuleLine:       "в %s нет директивы module",
+		InvalidGoVersion:           "недопустимая версия '%s', ожидается vMAJOR.MINOR.PATCH",
+		InvalidBump:                "недопустимое увеличение '%s', ожидается major, minor или patch",
+		GoModuleMajorMismatch:      "версия %s не соответствует пути модуля %s, который требует мажорную версию %d",
+		GoModuleMajorSuffixMissing: "версия %s требует, чтобы путь модуля %s заканчивался на /v%d",
+
+		InitDiscoverFlag:         "Предложить теги на основе каталогов cmd/*, модулей Go, Dockerfile и существующих тегов релизов",
+		DiscoverComponentsFailed: "Не удалось обнаружить теги: %v",
+		NoComponentsDiscovered:   "Новые теги не обнаружены",
+		ConfirmDiscoveredTag:     "Добавить тег '%s' (из %s)? (y/n): ",
+
+		InvalidTemplate:           "недопустимый шаблон тега '%s'",
+		UnknownTemplateField:      "неизвестный заполнитель '{%s}' в шаблоне тега '%s', поддерживаются: {component}, {timestamp}, {version}",
+		TemplateMissingComponent:  "шаблон тега '%s' должен содержать {component}",
+		NamingTemplateFields:      "шаблон тегов релизов '%s' должен содержать {component} и {timestamp} и не содержать {version}",
+		MigrateToInactiveTemplate: "'%s' не является tag_template в %s ('%s'): сначала укажите его там, иначе history, latest, rollback и yank не найдут перенесённые релизы",
+		ImportShort:               "Импортировать теги и историю из существующих тегов git",
+		ImportLong:                "Просканировать существующие теги git, соответствующие шаблону, например '{component}-v{version}', добавить обнаруженные теги в файл .rtag и показать их историю релизов.",
+		ImportPatternFlag:         "Шаблон тега с {component} и необязательными заполнителями {timestamp} или {version}, по умолчанию tag_template из .rtag.json",
+		ImportFailed:              "Не удалось импортировать теги: %v",
+		NoTagsMatchTemplate:       "Нет тегов git, соответствующих '%s'",
+		ImportedHistory:           "Обнаруженная история для '%s':",
+		ImportedComponent:         "  - %s: %d релизов (%s ... %s)",
+
+		MigrateTagsShort:         "Перенести теги релизов на новую схему именования",
+		MigrateTagsLong:          "Создать теги по шаблону --to на коммитах всех тегов, соответствующих шаблону --from, сохраняя аннотации и даты. Старые теги можно удалить локально и удалённо с помощью --delete-old.",
+		MigrateFromFlag:          "Шаблон существующих тегов, например '{component}-v{version}'",
+		MigrateToFlag:            "Шаблон новых тегов, по умолчанию tag_template из .rtag.json",
+		MigrateDeleteOldFlag:     "Удалить старые теги локально и удалённо после переноса",
+		MigrateDryRunFlag:        "Только показать соответствие тегов, ничего не изменяя",
+		MigrateReportFlag:        "Записать соответствие тегов в формате JSON в этот файл",
+		MigrateFailed:            "Не удалось перенести теги: %v",
+		MigrateDryRun:            "Пробный запуск: будет перенесено тегов: %d",
+		MigrateSuccess:           "Успешно перенесено тегов: %d",
+		TemplateFieldUnavailable: "заполнитель '{%s}' недоступен из шаблона '%s'",
+		MigrationConflict:        "теги '%s' и '%s' оба будут перенесены в '%s'",
+		MigrateKeepsYank:         "[отозван]",
+		MigrateYankFailed:        "Не удалось перенести отзыв %s на %s: %v",
+		ReadTagFailed:            "не удалось прочитать тег '%s'",
+		DeletingOldTags:          "Удаление старых тегов...",
+		DeleteOldTagsFailed:      "Не удалось удалить старые теги: %v",
+		WriteReportFailed:        "Не удалось записать отчёт: %v",
+		ReportWritten:            "Отчёт записан в %s",
+
+		WorkspaceShort:           "Работать с рабочим пространством из нескольких репозиториев",
+		WorkspaceLong:            "Выполнить list, status и push для всех репозиториев, перечисленных в файле рабочего пространства, по одному пути на строку.",
+		WorkspaceListShort:       "Показать теги каждого репозитория",
+		WorkspaceStatusShort:     "Показать ветку, локальные изменения и последние релизы каждого репозитория",
+		WorkspacePushShort:       "Отправить теги каждого репозитория с общей временной меткой",
+		WorkspaceFileFlag:        "Путь к файлу рабочего пространства",
+		WorkspaceReportFlag:      "Записать объединённый JSON-отчёт в этот файл",
+		WorkspaceFileOverride:    "--file и RTAG_FILE нельзя использовать с рабочими пространствами, каждый репозиторий использует свой файл .rtag",
+		ReadWorkspaceFailed:      "Не удалось прочитать рабочее пространство: %v",
+		WorkspaceEmpty:           "Рабочее пространство не содержит репозиториев",
+		WorkspaceRepo:            "== %s",
+		WorkspaceRepoFailed:      "Ошибка в %s: %s",
+		WorkspaceBranchClean:     "  ветка %s, нет локальных изменений",
+		WorkspaceBranchDirty:     "  ветка %s, локальных изменений: %d",
+		WorkspacePushRequiresAll: "Пожалуйста, используйте --all для отправки тегов каждого репозитория",
+		WorkspacePushSummary:     "Отправлено репозиториев: %d из %d (временная метка: %s)",
+
+		TimeoutFlag:              "Отменить операцию git, длящуюся дольше (например, 2m, 0 — без ограничения)",
+		ReleaseFailed:            "Выпуск остановлен: %v",
+		Interrupted:              "Прервано, удаление тегов, созданных этим запуском и не отправленных...",
+		InterruptedTagRemoved:    "  - удалён локальный тег %s",
+		InterruptedTagRestored:   "  - %s восстановлен на %s",
+		InterruptedCleanupFailed: "  - не удалось очистить %s: %v",
+		InterruptedDuringPush:    "Отправка прервана, эти теги уже могут существовать на удалённом репозитории: %s",
+
+		RetriesFlag:          "Сколько раз повторять push после временной ошибки (по умолчанию push_retries из .rtag.json, 3)",
+		PushAttempts:         "Попытки push:",
+		PushAttemptSucceeded: "  #%d: отправлено ссылок: %d",
+		PushAttemptRetrying:  "  #%d не удалась, повтор для ссылок (%d) через %s: %v",
+		PushAttemptFailed:    "  #%d не удалась: %v",
+
+		TagFileLocked: "%s изменяется другим процессом rtag, удалите %s.lock, если такой процесс не запущен",
+
+		LockShort:       "Показать или снять блокировки релизов на удалённом репозитории",
+		LockStatusShort: "Показать компоненты, которые сейчас выпускаются, и владельцев блокировок",
+		LockBreakShort:  "Снять блокировку релиза компонентов, например после сбоя релиза",
+		PushLockFlag:    "Блокировать компоненты на удалённом репозитории на время релиза (также release_lock в .rtag.json)",
+		AcquiringLocks:  "Блокировка %s на удалённом репозитории...",
+		ComponentLocked: "%s выпускается пользователем %s с %s (блокировка истекает %s), повторите позже или выполните 'rtag lock break %s'",
+		LockFailed:      "Не удалось заблокировать релиз: %v",
+		UnlockFailed:    "Не удалось снять блокировки, они истекут сами: %v",
+		InvalidLockTTL:  "недопустимое значение release_lock_ttl %q в .rtag.json",
+		ReadLocksFailed: "Не удалось прочитать блокировки релизов: %v",
+		NoLocks:         "Нет заблокированных компонентов",
+		LockHeld:        "  - %s: %s, с %s, истекает %s",
+		LockExpired:     "  - %s: %s, с %s, истекла %s",
+		LockBroken:      "Блокировка релиза %s снята",
+		NotLocked:       "%s не заблокирован",
+		BreakLockFailed: "Не удалось снять блокировку релиза %s: %v",
+
+		PolicyShort:          "Проверить политику релизов из .rtag.json",
+		PolicyCheckShort:     "Проверить политику релизов для тегов без выпуска",
+		PolicyCheckFailed:    "Не удалось проверить политику релизов: %v",
+		PolicyInvalidSetting: "недопустимая настройка политики %s: %q",
+		PolicyViolated:       "Политика релизов не разрешает этот релиз:",
+		PolicyPassed:         "Политика релизов разрешает выпуск %s",
+		PolicyBranch:         "  - ветка %s не разрешена для релизов, разрешены: %s",
+		PolicyCleanTree:      "  - в рабочем дереве незакоммиченных изменений: %s, оно должно быть чистым",
+		PolicyMinInterval:    "  - %s выпущен %s назад (%s), между релизами должно пройти не меньше %s",
+		PolicyApprovals:      "  - у выпускаемого коммита трейлеров Approved-by: %s, требуется %s",
+		PolicyReleasers:      "  - %s не разрешено выпускать релизы, разрешено: %s",
+		PolicyDays:           "  - релизы не разрешены в %s, разрешённые дни: %s",
+		PolicyHours:          "  - релизы не разрешены в %s, разрешённые часы: %s",
+
+		FreezeShort:          "Показать периоды заморозки релизов из .rtag.json",
+		FreezeListShort:      "Показать текущие и предстоящие заморозки релизов",
+		FreezeDaysFlag:       "Сколько дней вперёд показывать",
+		OverrideFreezeFlag:   "Выпустить релиз во время заморозки, требует --reason",
+		FreezeReasonFlag:     "Причина обхода заморозки, записывается в аннотацию тега",
+		FreezeCheckFailed:    "Не удалось проверить заморозки релизов: %v",
+		ReleaseFrozen:        "Релизы заморожены:",
+		FreezeWindowLine:     "  - %s: %s - %s (%s)",
+		FreezeActive:         "[активна]",
+		FreezeAllComponents:  "все компоненты",
+		FreezeOverrideHint:   "Используйте --override-freeze --reason \"...\", чтобы всё равно выпустить релиз",
+		FreezeReasonRequired: "--override-freeze требует указать --reason",
+		FreezeOverridden:     "Обход заморозки %s: %s",
+		NoFreezes:            "Нет заморозок релизов в ближайшие %d дн.",
+		FreezeListHeader:     "Заморозки релизов в ближайшие %d дн.:",
+
+		PushReportFlag:       "Записать JSON-отчёт о релизе, включая вывод хуков, в этот файл",
+		TrainReportFlag:      "Записать JSON-отчёт о релизном поезде, включая вывод хуков, релизы на хостинге и доставку вебхуков, в этот файл",
+		RunningHook:          "Запуск хука %s: %s",
+		RunningComponentHook: "Запуск хука %s: %s (%s)",
+		HookFailed:           "Хук %s завершился с ошибкой: %v",
+		InvalidHookTimeout:   "некорректный тайм-аут хука %q",
+		ReleaseAbortedByHook: "Релиз прерван хуком pre_release, теги не созданы",
+
+		NotifyShort:              "Управление вебхуками, уведомляемыми после релизов",
+		NotifyTestShort:          "Отправить тестовые данные настроенным вебхукам",
+		NotifyURLFlag:            "Отправить тестовые данные на этот URL вместо настроенных вебхуков",
+		NotifyAllFlag:            "Отправить тестовые данные на все настроенные вебхуки, включая рабочие",
+		NoWebhooks:               "В .rtag.json не настроены вебхуки",
+		NotifyTestTargetRequired: "Укажите --url, чтобы отправить тестовые данные на один адрес, или --all, чтобы отправить их на все настроенные вебхуки (%d)",
+		NotifyWebhookFlag:        "Номер настроенного вебхука, чьи тело, заголовки, тип содержимого и секрет использует --url",
+		NotifyWebhookNotFound:    "Вебхук %d не существует, настроено: %d",
+		WebhookSent:              "%s отправлено на %s (HTTP %d)",
+		WebhookRetrying:          "Вебхук %s не сработал (%v), повтор через %v",
+		WebhookFailed:            "Вебхук %s не сработал для %s: %v",
+		WebhookSecretMissing:     "секрет подписи %s не задан",
+
+		HostedReleaseCreated:      "Создан релиз %s %s: %s",
+		HostedReleaseURLUnknown:   "Создан релиз %s %s, его URL не удалось прочитать из ответа: %v",
+		HostedReleaseFailed:       "Не удалось создать релиз %s %s: %v",
+		HostedReleasesFailed:      "Релизы на хостинге пропущены: %v",
+		HostedReleasesUnreachable: "API %s %s недоступен, релизы на хостинге пропущены (теги отправлены): %v",
+		HostedReleaseTokenMissing: "Релизы на хостинге пропущены: задайте %s, чтобы создавать релизы на %s",
+		UnknownHostedProvider:     "неизвестный провайдер %q, используйте github, gitlab или gitea",
+		HostedRepositoryUnknown:   "не удалось определить репозиторий по origin %s, задайте repository и api_url",
 	}
 }
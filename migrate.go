@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// tagMigration maps an old tag to the tag created for it under the new template
+type tagMigration struct {
+	Old      string `json:"old"`
+	New      string `json:"new"`
+	Commit   string `json:"commit"`
+	Floating bool   `json:"floating,omitempty"` // a floating tag, moved to the new name
+	Yanked   bool   `json:"yanked,omitempty"`   // the yank of the old tag is carried over
+
+	ref        rtag.TagRef
+	yankReason string
+}
+
+func runMigrateTags(cmd *cobra.Command, args []string) {
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().MigrateFailed+"\n", err)
+		return
+	}
+
+	// Releases are only found under the active template, a dry run may
+	// preview another one before it is configured
+	to := migrateTo
+	if to == "" {
+		to = project.Naming().String()
+	} else if to != project.Naming().String() && !migrateDryRun {
+		fmt.Printf(T().MigrateFailed+"\n", fmt.Errorf(T().MigrateToInactiveTemplate, to, projectConfigFile, project.Naming()))
+		return
+	}
+
+	migrations, err := planTagMigrations(migrateFrom, to)
+	if err != nil {
+		fmt.Printf(T().MigrateFailed+"\n", err)
+		return
+	}
+
+	if len(migrations) == 0 {
+		fmt.Printf(T().NoTagsMatchTemplate+"\n", migrateFrom)
+		return
+	}
+
+	for _, m := range migrations {
+		line := fmt.Sprintf("  %s -> %s (%s)", m.Old, m.New, shortCommit(m.Commit))
+		if m.Yanked {
+			line += " " + T().MigrateKeepsYank
+		}
+		fmt.Println(line)
+	}
+
+	if migrateDryRun {
+		fmt.Printf(T().MigrateDryRun+"\n", len(migrations))
+		if migrateReport != "" {
+			writeReport(migrateReport, migrations)
+		}
+		return
+	}
+
+	if !checkPush() {
+		return
+	}
+
+	var created []tagMigration
+	var refspecs []string
+	for _, m := range migrations {
+		if runContext.Err() != nil {
+			return
+		}
+		if err := copyTag(m.ref, m.New, m.Floating); err != nil {
+			fmt.Printf(T().CreateTagFailed+"\n", m.New, err)
+			continue
+		}
+		if m.Floating {
+			refspecs = append(refspecs, "+"+rtag.TagRefspec(m.New))
+		} else {
+			refspecs = append(refspecs, rtag.TagRefspec(m.New))
+		}
+
+		if m.Yanked {
+			yankedRef := rtag.YankedRefPrefix + m.New
+			if err := gitRepo().WriteBlobRef(runContext, yankedRef, m.yankReason); err != nil {
+				fmt.Printf(T().MigrateYankFailed+"\n", m.Old, m.New, err)
+				m.Yanked = false
+			} else {
+				refspecs = append(refspecs, yankedRef+":"+yankedRef)
+			}
+		}
+		created = append(created, m)
+	}
+
+	if !pushRefspecs(refspecs) {
+		return
+	}
+
+	if migrateReport != "" {
+		writeReport(migrateReport, created)
+	}
+
+	if migrateDeleteOld {
+		deleteMigratedTags(created)
+	}
+
+	fmt.Printf(T().MigrateSuccess+"\n", len(created))
+}
+
+// planTagMigrations maps every tag matching the old template to its name under
+// the new template. The new template may only use placeholders of the old one,
+// except {timestamp} which falls back to the tag's creation date. Yanks move
+// with their tags, and when both templates have a {timestamp} the floating
+// tags of the migrated components are renamed as well.
+func planTagMigrations(from, to string) ([]tagMigration, error) {
+	fromTemplate, err := rtag.ParseTemplate(from)
+	if err != nil {
+		return nil, localizeError(err)
+	}
+
+	toTemplate, err := rtag.ParseTemplate(to)
+	if err != nil {
+		return nil, localizeError(err)
+	}
+
+	for _, field := range toTemplate.Fields() {
+		if !fromTemplate.Has(field) && field != "timestamp" {
+			return nil, fmt.Errorf(T().TemplateFieldUnavailable, field, from)
+		}
+	}
+
+	refs, err := listTagRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	fetchYanked()
+	yanked, err := rtag.Yanked(runContext, gitRepo())
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]rtag.TagRef, len(refs))
+	for _, ref := range refs {
+		existing[ref.Name] = ref
+	}
+
+	var components []string
+
+	var migrations []tagMigration
+	planned := make(map[string]string)
+	for _, ref := range refs {
+		values, ok := fromTemplate.Match(ref.Name)
+		if !ok {
+			continue
+		}
+
+		if _, ok := values["timestamp"]; !ok {
+			values["timestamp"] = ref.Date.Format(rtag.TimestampFormat)
+		}
+
+		if !slices.Contains(components, values["component"]) {
+			components = append(components, values["component"])
+		}
+
+		name := toTemplate.Format(values)
+		if _, ok := existing[name]; name == ref.Name || ok {
+			continue
+		}
+
+		if previous, ok := planned[name]; ok {
+			return nil, fmt.Errorf(T().MigrationConflict, previous, ref.Name, name)
+		}
+		planned[name] = ref.Name
+
+		m := tagMigration{Old: ref.Name, New: name, Commit: ref.Commit, ref: ref}
+		m.yankReason, m.Yanked = yanked[ref.Name]
+		migrations = append(migrations, m)
+	}
+
+	if !fromTemplate.Has("timestamp") || !toTemplate.Has("timestamp") {
+		return migrations, nil
+	}
+	for _, component := range components {
+		old, name := fromTemplate.FloatingName(component), toTemplate.FloatingName(component)
+		ref, ok := existing[old]
+		if !ok || old == name {
+			continue
+		}
+		if previous, ok := planned[name]; ok {
+			return nil, fmt.Errorf(T().MigrationConflict, previous, old, name)
+		}
+		planned[name] = old
+		migrations = append(migrations, tagMigration{Old: old, New: name, Commit: ref.Commit, Floating: true, ref: ref})
+	}
+
+	return migrations, nil
+}
+
+// copyTag creates a tag on the same commit as ref, replacing an existing one
+// if force is set. Annotated tags keep their message, tagger and date,
+// lightweight tags stay lightweight.
+func copyTag(ref rtag.TagRef, name string, force bool) error {
+	if ref.Object == ref.Commit {
+		return createTag(name, ref.Commit, rtag.TagOptions{Force: force})
+	}
+
+	info, err := gitRepo().TagInfo(runContext, ref.Name)
+	if err != nil {
+		return fmt.Errorf(T().ReadTagFailed, ref.Name)
+	}
+
+	return createTag(name, ref.Commit, rtag.TagOptions{Message: info.Message, Tagger: &info.Tagger, Force: force})
+}
+
+// deleteMigratedTags removes the old tags and their yanks locally and from the remote
+func deleteMigratedTags(migrations []tagMigration) {
+	if len(migrations) == 0 {
+		return
+	}
+
+	var names, yanks, refspecs []string
+	for _, m := range migrations {
+		names = append(names, m.Old)
+		refspecs = append(refspecs, ":refs/tags/"+m.Old)
+		if m.Yanked {
+			yanks = append(yanks, rtag.YankedRefPrefix+m.Old)
+			refspecs = append(refspecs, ":"+rtag.YankedRefPrefix+m.Old)
+		}
+	}
+
+	fmt.Println(T().DeletingOldTags)
+	if err := pushRefs(refspecs); err != nil {
+		fmt.Printf(T().DeleteOldTagsFailed+"\n", err)
+		return
+	}
+	if err := gitRepo().DeleteTags(runContext, names); err != nil {
+		fmt.Printf(T().DeleteOldTagsFailed+"\n", err)
+	}
+	for _, ref := range yanks {
+		if err := gitRepo().DeleteBlobRef(runContext, ref); err != nil {
+			fmt.Printf(T().DeleteOldTagsFailed+"\n", err)
+		}
+	}
+}
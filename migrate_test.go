@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTagTemplateNamesReleases(t *testing.T) {
+	dir := newTestRepo(t, "api\n", `{"tag_template": "rel-{timestamp}-{component}"}`)
+
+	if output, status := runRtag(t, dir, "push", "api"); status != 0 {
+		t.Fatalf("rtag push exited with %d:\n%s", status, output)
+	}
+	tag := runGit(t, dir, "tag", "--list")
+	if !strings.HasPrefix(tag, "rel-") || !strings.HasSuffix(tag, "-api") {
+		t.Fatalf("rtag push created %q, want a rel-{timestamp}-api tag", tag)
+	}
+	if output, _ := runRtag(t, dir, "history", "api"); !strings.Contains(output, tag) {
+		t.Errorf("rtag history does not list %s:\n%s", tag, output)
+	}
+
+	// Migrating to another template would hide the releases from history
+	output, _ := runRtag(t, dir, "migrate-tags", "--from", "rel-{timestamp}-{component}", "--to", "release-{timestamp}-{component}", "--delete-old")
+	if !strings.Contains(output, "not the tag_template") {
+		t.Errorf("rtag migrate-tags to an inactive template did not refuse:\n%s", output)
+	}
+	if tags := runGit(t, dir, "tag", "--list"); tags != tag {
+		t.Errorf("a refused migration changed the tags to:\n%s", tags)
+	}
+
+	// A dry run may preview it
+	output, _ = runRtag(t, dir, "migrate-tags", "--from", "rel-{timestamp}-{component}", "--to", "release-{timestamp}-{component}", "--dry-run")
+	if !strings.Contains(output, "release-"+strings.TrimPrefix(tag, "rel-")) {
+		t.Errorf("rtag migrate-tags --dry-run does not show the mapping:\n%s", output)
+	}
+}
+
+func TestTagTemplateMustNameReleases(t *testing.T) {
+	dir := newTestRepo(t, "api\n", `{"tag_template": "{component}-v{version}"}`)
+
+	output, status := runRtag(t, dir, "push", "api")
+	if status != 1 || !strings.Contains(output, "must contain {component} and {timestamp}") {
+		t.Errorf("rtag push with an invalid tag_template exited with %d:\n%s", status, output)
+	}
+}
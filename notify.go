@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// webhookTimeout bounds one delivery attempt
+const webhookTimeout = 10 * time.Second
+
+// webhookChangelogLimit is the number of commit subjects sent in the changelog excerpt
+const webhookChangelogLimit = 20
+
+// signatureHeader carries the HMAC-SHA256 of the body, as "sha256=<hex>"
+const signatureHeader = "X-Rtag-Signature-256"
+
+// webhook is an HTTP endpoint notified after each released component
+type webhook struct {
+	URL         string            `json:"url"`
+	Body        string            `json:"body"`         // text/template rendering the body, the JSON payload by default
+	ContentType string            `json:"content_type"` // application/json by default
+	Headers     map[string]string `json:"headers"`      // values may reference environment variables, e.g. "Bearer $CHAT_TOKEN"
+	SecretEnv   string            `json:"secret_env"`   // environment variable holding the HMAC signing key
+	Components  []string          `json:"components"`   // notified components, patterns allowed, all of them when empty
+}
+
+// webhookPayload describes a released component to webhooks and body templates
+type webhookPayload struct {
+	Event     string   `json:"event"` // "release", or "test" for rtag notify test
+	Component string   `json:"component"`
+	Tag       string   `json:"tag"`
+	Commit    string   `json:"commit"`
+	Timestamp string   `json:"timestamp"`
+	Releaser  string   `json:"releaser"`
+	Previous  string   `json:"previous,omitempty"` // previous release tag of the component
+	Changelog []string `json:"changelog"`          // subjects of the released commits, newest first
+}
+
+// notifyResult is the outcome of one webhook delivery, as written to release reports
+type notifyResult struct {
+	URL       string `json:"url"`
+	Component string `json:"component"`
+	Status    int    `json:"status,omitempty"`
+	Attempts  int    `json:"attempts"`
+	Error     string `json:"error,omitempty"`
+}
+
+// httpStatusError is a request refused by a webhook receiver or a hosting API
+type httpStatusError struct {
+	Status int
+	Body   string
+}
+
+func (e *httpStatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("HTTP %d", e.Status)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.Status, e.Body)
+}
+
+// notifyReleases posts a payload for each tag released at commit to the
+// configured webhooks, with the changelog since the previous tag in history.
+// Failed deliveries are reported and do not affect the release.
+func notifyReleases(config projectConfig, timestamp, commit string, releases []componentRelease, history map[string][]rtag.Release) []notifyResult {
+	if len(config.Webhooks) == 0 || len(releases) == 0 {
+		return nil
+	}
+
+	releaser := ""
+	if identity, err := gitRepo().Identity(runContext); err == nil {
+		releaser = fmt.Sprintf("%s <%s>", identity.Name, identity.Email)
+	}
+
+	var results []notifyResult
+	for _, release := range releases {
+		component := release.Component
+		payload := webhookPayload{
+			Event:     "release",
+			Component: component,
+			Tag:       release.Tag,
+			Commit:    commit,
+			Timestamp: timestamp,
+			Releaser:  releaser,
+		}
+		var commits []rtag.CommitInfo
+		payload.Previous, commits = releaseCommits(history[component], payload.Tag, commit)
+		for _, c := range commits {
+			if len(payload.Changelog) == webhookChangelogLimit {
+				break
+			}
+			payload.Changelog = append(payload.Changelog, c.Subject)
+		}
+
+		for _, hook := range config.Webhooks {
+			if len(hook.Components) > 0 && !rtag.MatchAny(hook.Components, component) {
+				continue
+			}
+			results = append(results, deliverWebhook(hook, payload, config.WebhookRetries))
+		}
+	}
+	return results
+}
+
+// releaseCommits returns the release of a component before tag and the
+// commits released since, newest first
+func releaseCommits(history []rtag.Release, tag, commit string) (string, []rtag.CommitInfo) {
+	previous, since := "", ""
+	for _, release := range history {
+		if release.Tag == tag {
+			break
+		}
+		previous, since = release.Tag, release.Commit
+	}
+
+	commits, err := gitRepo().Log(runContext, since, commit)
+	if err != nil {
+		return previous, nil
+	}
+	return previous, commits
+}
+
+// deliverWebhook posts payload to a webhook, retrying network errors, 429
+// and 5xx responses with exponential backoff
+func deliverWebhook(hook webhook, payload webhookPayload, retries int) notifyResult {
+	result := notifyResult{URL: hook.URL, Component: payload.Component}
+
+	body, err := renderWebhookBody(hook, payload)
+	if err == nil && hook.SecretEnv != "" && os.Getenv(hook.SecretEnv) == "" {
+		err = fmt.Errorf(T().WebhookSecretMissing, hook.SecretEnv)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		fmt.Printf(T().WebhookFailed+"\n", hook.URL, payload.Component, err)
+		return result
+	}
+
+	delay := rtag.DefaultRetryPolicy.Delay
+	for {
+		result.Attempts++
+		result.Status, err = postWebhook(hook, body)
+		if err == nil {
+			fmt.Printf(T().WebhookSent+"\n", payload.Component, hook.URL, result.Status)
+			return result
+		}
+
+		if result.Attempts > retries || !retryableWebhookError(err) || runContext.Err() != nil {
+			result.Error = err.Error()
+			fmt.Printf(T().WebhookFailed+"\n", hook.URL, payload.Component, err)
+			return result
+		}
+
+		fmt.Printf(T().WebhookRetrying+"\n", hook.URL, err, delay)
+		select {
+		case <-runContext.Done():
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, rtag.DefaultRetryPolicy.MaxDelay)
+	}
+}
+
+// renderWebhookBody renders the body template of a webhook, or the JSON payload when it has none
+func renderWebhookBody(hook webhook, payload webhookPayload) ([]byte, error) {
+	if hook.Body == "" {
+		return marshalWebhookJSON(payload)
+	}
+
+	tmpl, err := template.New(hook.URL).Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := marshalWebhookJSON(v)
+			return string(data), err
+		},
+		"join": strings.Join,
+	}).Parse(hook.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, payload); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// marshalWebhookJSON encodes v without escaping <, > and &, which appear in
+// releasers such as "Jane <jane@example.com>"
+func marshalWebhookJSON(v any) ([]byte, error) {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(data.Bytes(), []byte("\n")), nil
+}
+
+// postWebhook sends one delivery and returns the response status
+func postWebhook(hook webhook, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(runContext, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	contentType := hook.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "rtag")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+	if hook.SecretEnv != "" {
+		mac := hmac.New(sha256.New, []byte(os.Getenv(hook.SecretEnv)))
+		mac.Write(body)
+		req.Header.Set(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return resp.StatusCode, &httpStatusError{Status: resp.StatusCode, Body: strings.TrimSpace(string(message))}
+	}
+	return resp.StatusCode, nil
+}
+
+// retryableWebhookError reports whether a failed delivery may succeed later.
+// Receivers refusing the request with a 4xx other than 429 will refuse it
+// again, and so will a URL that cannot be parsed or has an unsupported
+// scheme. Network errors, timeouts and dropped connections are retried.
+func retryableWebhookError(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status == http.StatusTooManyRequests || statusErr.Status >= 500
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		var netErr net.Error
+		return urlErr.Timeout() || errors.As(urlErr.Err, &netErr) || errors.Is(urlErr.Err, io.EOF) || errors.Is(urlErr.Err, io.ErrUnexpectedEOF)
+	}
+	return true
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) {
+	config, err := loadProjectConfig()
+	if err != nil {
+		fmt.Printf(T().LoadConfigFailed+"\n", err)
+		return
+	}
+
+	hooks := config.Webhooks
+	if notifyURL != "" {
+		// The local receiver gets the body, headers and signature of a
+		// configured webhook, so that they can be checked safely
+		hook := webhook{}
+		if len(config.Webhooks) > 0 {
+			if notifyWebhook < 1 || notifyWebhook > len(config.Webhooks) {
+				fmt.Printf(T().NotifyWebhookNotFound+"\n", notifyWebhook, len(config.Webhooks))
+				return
+			}
+			hook = config.Webhooks[notifyWebhook-1]
+		}
+		hook.URL = notifyURL
+		hooks = []webhook{hook}
+	}
+	if len(hooks) == 0 {
+		fmt.Println(T().NoWebhooks)
+		return
+	}
+
+	// The configured webhooks are the production ones, such as deploy triggers
+	if notifyURL == "" && !notifyAll {
+		fmt.Printf(T().NotifyTestTargetRequired+"\n", len(hooks))
+		return
+	}
+
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	component := "example"
+	if content, err := readTagFile(); err == nil && len(content.Tags) > 0 {
+		component = content.Tags[0]
+	}
+
+	timestamp := time.Now().Format(rtag.TimestampFormat)
+	payload := webhookPayload{
+		Event:     "test",
+		Component: component,
+		Tag:       project.Naming().Format(map[string]string{"timestamp": timestamp, "component": component}),
+		Timestamp: timestamp,
+		Changelog: []string{},
+	}
+	if commit, err := resolveCommit("HEAD"); err == nil {
+		payload.Commit = commit
+	}
+	if identity, err := gitRepo().Identity(runContext); err == nil {
+		payload.Releaser = fmt.Sprintf("%s <%s>", identity.Name, identity.Email)
+	}
+
+	for _, hook := range hooks {
+		deliverWebhook(hook, payload, 0)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotifyTestURLKeepsWebhookSettings(t *testing.T) {
+	type delivery struct {
+		header http.Header
+		body   string
+	}
+	deliveries := make(chan delivery, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{r.Header, string(body)}
+	}))
+	defer server.Close()
+
+	t.Setenv("RTAG_TEST_SECRET", "s3cret")
+	t.Setenv("RTAG_TEST_TOKEN", "t0ken")
+	dir := newTestRepo(t, "api\n", `{"webhooks": [
+		{"url": "https://hooks.example.com/deploy"},
+		{"url": "https://chat.example.com/hook", "secret_env": "RTAG_TEST_SECRET", "content_type": "text/plain",
+		 "headers": {"Authorization": "Bearer $RTAG_TEST_TOKEN"}, "body": "{{.Event}} {{.Component}}"}
+	]}`)
+
+	output, _ := runRtag(t, dir, "notify", "test", "--url", server.URL, "--webhook", "2")
+	if len(deliveries) != 1 {
+		t.Fatalf("the receiver got %d deliveries, want 1:\n%s", len(deliveries), output)
+	}
+	got := <-deliveries
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(got.body))
+	for name, want := range map[string]string{
+		signatureHeader: "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+		"Authorization": "Bearer t0ken",
+		"Content-Type":  "text/plain",
+	} {
+		if value := got.header.Get(name); value != want {
+			t.Errorf("%s = %q, want %q", name, value, want)
+		}
+	}
+	if got.body != "test api" {
+		t.Errorf("body = %q, want the rendered template", got.body)
+	}
+
+	output, _ = runRtag(t, dir, "notify", "test", "--url", server.URL, "--webhook", "3")
+	if len(deliveries) != 0 || !strings.Contains(output, "Webhook 3 does not exist") {
+		t.Errorf("rtag notify test --webhook 3 with 2 webhooks:\n%s", output)
+	}
+}
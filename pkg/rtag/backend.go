@@ -0,0 +1,176 @@
+package rtag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Backend is the set of git operations used by rtag. The exec backend runs
+// the git binary, the native backend reads and writes the repository directly
+// and the memory backend keeps a repository in memory.
+type Backend interface {
+	// Root returns the top-level directory of the working tree
+	Root(ctx context.Context) (string, error)
+	// Branch returns the checked out branch, or HEAD when detached
+	Branch(ctx context.Context) (string, error)
+	// ChangedFiles returns the number of paths with uncommitted changes
+	ChangedFiles(ctx context.Context) (int, error)
+	// Identity returns the name and email new objects are created with
+	Identity(ctx context.Context) (Signature, error)
+
+	// ResolveRef returns the commit a ref, tag name or hash points to
+	ResolveRef(ctx context.Context, ref string) (string, error)
+	// RefExists reports whether a fully qualified ref exists
+	RefExists(ctx context.Context, ref string) (bool, error)
+	// Log returns the commits reachable from until but not from since, newest first.
+	// An empty since returns the whole history of until.
+	Log(ctx context.Context, since, until string) ([]CommitInfo, error)
+
+	// ListTags returns all tags ordered by creation date
+	ListTags(ctx context.Context) ([]TagRef, error)
+	// TagInfo returns the message and tagger of an annotated tag
+	TagInfo(ctx context.Context, name string) (TagInfo, error)
+	// CreateTag creates a tag on the commit target resolves to. Tags with a
+	// message are annotated, tags without one are lightweight.
+	CreateTag(ctx context.Context, name, target string, opts TagOptions) error
+	// UpdateTags creates or moves tags in a single transaction: either all of
+	// them are updated or none is
+	UpdateTags(ctx context.Context, updates []TagUpdate) error
+	// DeleteTags deletes local tags
+	DeleteTags(ctx context.Context, names []string) error
+
+	// WriteBlobRef stores content in a blob and creates ref pointing at it. It
+	// fails if ref already exists.
+	WriteBlobRef(ctx context.Context, ref, content string) error
+	// DeleteBlobRef deletes a ref created by WriteBlobRef
+	DeleteBlobRef(ctx context.Context, ref string) error
+	// ReadBlobRefs returns the content of the blobs referenced below prefix, keyed by ref name without prefix
+	ReadBlobRefs(ctx context.Context, prefix string) (map[string]string, error)
+	// WriteBlob stores content in a blob and returns its hash
+	WriteBlob(ctx context.Context, content string) (string, error)
+	// ReadBlob returns the content of a blob
+	ReadBlob(ctx context.Context, hash string) (string, error)
+
+	// PushRefs pushes refspecs to a remote. When the remote accepts some refs
+	// and not others, the error is a *PushError listing the refused ones.
+	PushRefs(ctx context.Context, remote string, refspecs []string) error
+	// CheckPush fails, without contacting the remote, when the backend cannot
+	// push to it, e.g. with ErrUnsupported. Callers check it before creating
+	// tags they would not be able to push.
+	CheckPush(ctx context.Context, remote string) error
+	// UpdateRemoteRef points ref on a remote at the local object hash, or
+	// deletes it when hash is empty, provided that ref currently points at
+	// old. An empty old requires that ref does not exist yet. The check and
+	// the update are done atomically by the remote.
+	UpdateRemoteRef(ctx context.Context, remote, ref, old, hash string) error
+	// RemoteURL returns the URL a remote fetches from
+	RemoteURL(ctx context.Context, remote string) (string, error)
+	// RemoteRefs returns the refs of a remote below prefix, mapped to the object they point to
+	RemoteRefs(ctx context.Context, remote, prefix string) (map[string]string, error)
+	// FetchRefs copies the objects the refs of a remote point to, without updating local refs
+	FetchRefs(ctx context.Context, remote string, refs []string) error
+}
+
+// TagRef describes a tag of the repository
+type TagRef struct {
+	Name   string
+	Object string // the tag object for annotated tags, otherwise the commit
+	Commit string
+	Date   time.Time
+}
+
+// Signature identifies who created an object and when
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// TagOptions controls how a tag is created
+type TagOptions struct {
+	Message string
+	Tagger  *Signature // defaults to the configured user and the current time
+	Force   bool       // replace an existing tag
+}
+
+// TagUpdate is a tag written by UpdateTags
+type TagUpdate struct {
+	Name    string
+	Commit  string // hash of the commit to tag
+	Force   bool   // move the tag if it already exists
+	Message string // annotates the tag with the configured identity, lightweight tags have none
+}
+
+// TagInfo is the content of an annotated tag
+type TagInfo struct {
+	Message string
+	Tagger  Signature
+}
+
+// CommitInfo summarizes a commit
+type CommitInfo struct {
+	Hash    string
+	Author  Signature
+	Subject string
+	Body    string // the message after the subject line
+}
+
+// GitError is returned when a git operation fails
+type GitError struct {
+	Op       string
+	ExitCode int
+	Stderr   string
+}
+
+func (e *GitError) Error() string {
+	message := strings.TrimSpace(e.Stderr)
+	if message == "" {
+		message = fmt.Sprintf("exit status %d", e.ExitCode)
+	}
+	return fmt.Sprintf("git %s: %s", e.Op, message)
+}
+
+// RefRejection is a refspec the remote did not accept
+type RefRejection struct {
+	Refspec string
+	Reason  string // e.g. "already exists" or "cannot lock ref"
+}
+
+// PushError reports a push in which the remote refused some refs. The refs
+// not listed in Rejected were updated.
+type PushError struct {
+	Rejected []RefRejection
+	Err      error // the failure reported by git, if any
+}
+
+func (e *PushError) Error() string {
+	reasons := make([]string, len(e.Rejected))
+	for i, rejection := range e.Rejected {
+		reasons[i] = fmt.Sprintf("%s (%s)", rejection.Refspec, rejection.Reason)
+	}
+	return "push rejected: " + strings.Join(reasons, ", ")
+}
+
+func (e *PushError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNotFound is returned when a ref or object does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrUnsupported is returned by backends that cannot perform an operation
+var ErrUnsupported = errors.New("not supported by this git backend")
+
+// DefaultBackend returns the backend for the repository containing dir: the
+// git binary when it is installed and the native backend otherwise, which
+// only pushes to remotes on the local file system
+func DefaultBackend(dir string) Backend {
+	if _, err := exec.LookPath("git"); err == nil {
+		return NewExecBackend(dir)
+	}
+	return NewNativeBackend(dir)
+}
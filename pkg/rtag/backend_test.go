@@ -0,0 +1,654 @@
+package rtag
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fixture is a repository built with the git binary: four commits on main,
+// the first three packed with their refs and the last one loose, lightweight
+// and annotated tags, and a loose tag overriding a packed one
+type fixture struct {
+	dir     string
+	remote  string   // bare repository configured as origin
+	commits []string // oldest first
+	blob    string   // data.txt at the third commit, stored as a delta
+	content string   // content of blob
+}
+
+// backendKinds are the backends every test runs against
+var backendKinds = []string{"exec", "native", "memory"}
+
+// runGit runs git in dir and returns its trimmed output
+func runGit(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// setGitEnv isolates git from the user configuration and fixes the identity
+func setGitEnv(t testing.TB) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, role := range []string{"AUTHOR", "COMMITTER"} {
+		t.Setenv("GIT_"+role+"_NAME", "Tester")
+		t.Setenv("GIT_"+role+"_EMAIL", "tester@example.com")
+	}
+}
+
+// commitAt commits everything in dir with fixed dates so that history and tag order are stable
+func commitAt(t testing.TB, dir, message, date string) string {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_DATE", date)
+	t.Setenv("GIT_COMMITTER_DATE", date)
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", message)
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
+
+// newFixture builds the fixture repository. The pack stores deltas against
+// base offsets (OFS_DELTA) or base hashes (REF_DELTA).
+func newFixture(t testing.TB, offsetDeltas bool) *fixture {
+	t.Helper()
+	setGitEnv(t)
+
+	f := &fixture{dir: t.TempDir(), remote: t.TempDir()}
+	runGit(t, f.dir, "init", "-q", "-b", "main")
+	runGit(t, f.remote, "init", "-q", "--bare")
+	runGit(t, f.dir, "remote", "add", "origin", f.remote)
+
+	// A file large enough for git to store its later versions as deltas
+	lines := make([]string, 300)
+	for i := range lines {
+		lines[i] = strings.Repeat("line ", 8) + string(rune('a'+i%26))
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(f.dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("data.txt", strings.Join(lines, "\n"))
+	f.commits = append(f.commits, commitAt(t, f.dir, "first", "2024-01-01T10:00:00Z"))
+	lines[10] = "changed in the second commit"
+	write("data.txt", strings.Join(lines, "\n"))
+	f.commits = append(f.commits, commitAt(t, f.dir, "second", "2024-01-02T10:00:00Z"))
+	lines[200] = "changed in the third commit"
+	f.content = strings.Join(lines, "\n")
+	write("data.txt", f.content)
+	write("notes.txt", "notes\n")
+	f.commits = append(f.commits, commitAt(t, f.dir, "third", "2024-01-03T10:00:00Z"))
+	f.blob = runGit(t, f.dir, "rev-parse", "HEAD:data.txt")
+
+	runGit(t, f.dir, "tag", "light-v1", f.commits[0])
+	runGit(t, f.dir, "tag", "release-202401010000-api", f.commits[0])
+	runGit(t, f.dir, "tag", "-a", "-m", "Release api", "release-202401020000-api", f.commits[1])
+	runGit(t, f.dir, "tag", "-a", "-m", "Release cron\n\nWith a body", "release-202401030000-cron", f.commits[2])
+
+	deltaBaseOffset := "false"
+	if offsetDeltas {
+		deltaBaseOffset = "true"
+	}
+	runGit(t, f.dir, "-c", "repack.useDeltaBaseOffset="+deltaBaseOffset, "repack", "-a", "-d", "-f", "-q", "--window=50", "--depth=50")
+	runGit(t, f.dir, "pack-refs", "--all", "--prune")
+	packs, _ := filepath.Glob(filepath.Join(f.dir, ".git", "objects", "pack", "*.idx"))
+	if len(packs) != 1 || !strings.Contains(runGit(t, f.dir, "verify-pack", "-v", packs[0]), "chain length = ") {
+		t.Fatal("the fixture pack has no deltas")
+	}
+
+	// Loose objects and refs on top of the pack
+	write("notes.txt", "more notes\n")
+	f.commits = append(f.commits, commitAt(t, f.dir, "fourth", "2024-01-04T10:00:00Z"))
+	runGit(t, f.dir, "tag", "-f", "light-v1", f.commits[1])
+	runGit(t, f.dir, "tag", "loose-v4", f.commits[3])
+	return f
+}
+
+// backend opens the fixture with a backend. The memory backend gets a copy
+// of the objects and refs of the repository.
+func (f *fixture) backend(t testing.TB, kind string) Backend {
+	t.Helper()
+	switch kind {
+	case "exec":
+		return NewExecBackend(f.dir)
+	case "native":
+		return NewNativeBackend(f.dir)
+	}
+
+	src := &fileStorage{dir: f.dir}
+	refs, err := src.refs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMemoryBackend(f.dir)
+	for name, hash := range refs {
+		if err := copyObjects(context.Background(), src, m.repo, hash); err != nil {
+			t.Fatal(err)
+		}
+		m.repo.refMap[name] = hash
+	}
+
+	// The remote starts with the objects of main, like the bare repository after a push
+	remote := m.AddRemote("origin")
+	if err := copyObjects(context.Background(), src, remote.repo, f.commits[len(f.commits)-1]); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// pushMain gives the bare remote the objects of main
+func (f *fixture) pushMain(t testing.TB) {
+	t.Helper()
+	runGit(t, f.dir, "push", "-q", "origin", "main")
+}
+
+func TestBackendsReadFixture(t *testing.T) {
+	for _, deltas := range []struct {
+		name   string
+		offset bool
+	}{{"ofs-delta", true}, {"ref-delta", false}} {
+		f := newFixture(t, deltas.offset)
+		for _, kind := range backendKinds {
+			t.Run(deltas.name+"/"+kind, func(t *testing.T) {
+				ctx := context.Background()
+				b := f.backend(t, kind)
+
+				if branch, err := b.Branch(ctx); err != nil || branch != "main" {
+					t.Errorf("Branch() = %q, %v, want main", branch, err)
+				}
+
+				for ref, want := range map[string]string{
+					"HEAD":                      f.commits[3],
+					"main":                      f.commits[3],
+					"release-202401020000-api":  f.commits[1], // annotated, peeled
+					"light-v1":                  f.commits[1], // loose overrides packed
+					"release-202401010000-api":  f.commits[0], // packed only
+					"refs/tags/loose-v4":        f.commits[3],
+					"release-202401030000-cron": f.commits[2],
+				} {
+					if got, err := b.ResolveRef(ctx, ref); err != nil || got != want {
+						t.Errorf("ResolveRef(%q) = %q, %v, want %q", ref, got, err, want)
+					}
+				}
+				if _, err := b.ResolveRef(ctx, "no-such-tag"); err == nil {
+					t.Error("ResolveRef(no-such-tag) succeeded")
+				}
+
+				for ref, want := range map[string]bool{
+					"refs/tags/release-202401010000-api": true,
+					"refs/tags/loose-v4":                 true,
+					"refs/heads/main":                    true,
+					"refs/tags/missing":                  false,
+				} {
+					if got, err := b.RefExists(ctx, ref); err != nil || got != want {
+						t.Errorf("RefExists(%q) = %v, %v, want %v", ref, got, err, want)
+					}
+				}
+
+				tags, err := b.ListTags(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				commits := make(map[string]string)
+				for _, tag := range tags {
+					commits[tag.Name] = tag.Commit
+					annotated := strings.HasPrefix(tag.Name, "release-2024010") && tag.Name != "release-202401010000-api"
+					if annotated == (tag.Object == tag.Commit) {
+						t.Errorf("tag %s: object %s, commit %s, annotated %v", tag.Name, tag.Object, tag.Commit, annotated)
+					}
+				}
+				wantCommits := map[string]string{
+					"light-v1":                  f.commits[1],
+					"loose-v4":                  f.commits[3],
+					"release-202401010000-api":  f.commits[0],
+					"release-202401020000-api":  f.commits[1],
+					"release-202401030000-cron": f.commits[2],
+				}
+				if !reflect.DeepEqual(commits, wantCommits) {
+					t.Errorf("ListTags() = %v, want %v", commits, wantCommits)
+				}
+
+				info, err := b.TagInfo(ctx, "release-202401030000-cron")
+				if err != nil || info.Message != "Release cron\n\nWith a body" || info.Tagger.Name != "Tester" || info.Tagger.Email != "tester@example.com" {
+					t.Errorf("TagInfo() = %+v, %v", info, err)
+				}
+				if _, err := b.TagInfo(ctx, "light-v1"); err == nil {
+					t.Error("TagInfo() of a lightweight tag succeeded")
+				}
+
+				log, err := b.Log(ctx, f.commits[0], "HEAD")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var subjects []string
+				for _, c := range log {
+					subjects = append(subjects, c.Subject)
+				}
+				if want := []string{"fourth", "third", "second"}; !reflect.DeepEqual(subjects, want) {
+					t.Errorf("Log() subjects = %v, want %v", subjects, want)
+				}
+				if all, err := b.Log(ctx, "", "HEAD"); err != nil || len(all) != 4 || all[3].Hash != f.commits[0] {
+					t.Errorf("Log(\"\", HEAD) = %d commits, %v", len(all), err)
+				}
+
+				if content, err := b.ReadBlob(ctx, f.blob); err != nil || content != f.content {
+					t.Errorf("ReadBlob() of a deltified blob = %d bytes, %v, want %d bytes", len(content), err, len(f.content))
+				}
+			})
+		}
+	}
+}
+
+func TestBackendsUpdateTags(t *testing.T) {
+	for _, kind := range backendKinds {
+		t.Run(kind, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture(t, true)
+			b := f.backend(t, kind)
+
+			zero := strings.Repeat("0", 40)
+			for _, tc := range []struct {
+				name    string
+				updates []TagUpdate
+			}{
+				{"existing tag without force", []TagUpdate{
+					{Name: "atomic-a", Commit: f.commits[0]},
+					{Name: "release-202401010000-api", Commit: f.commits[1]},
+				}},
+				{"unknown commit", []TagUpdate{
+					{Name: "atomic-b", Commit: f.commits[0], Message: "annotated"},
+					{Name: "atomic-c", Commit: zero},
+				}},
+			} {
+				if err := b.UpdateTags(ctx, tc.updates); err == nil {
+					t.Errorf("%s: UpdateTags() succeeded", tc.name)
+				}
+				for _, update := range tc.updates[:1] {
+					if exists, _ := b.RefExists(ctx, "refs/tags/"+update.Name); exists {
+						t.Errorf("%s: %s was created by a failed UpdateTags()", tc.name, update.Name)
+					}
+				}
+			}
+			if commit, _ := b.ResolveRef(ctx, "release-202401010000-api"); commit != f.commits[0] {
+				t.Errorf("a failed UpdateTags() moved release-202401010000-api to %s", commit)
+			}
+
+			err := b.UpdateTags(ctx, []TagUpdate{
+				{Name: "new-light", Commit: f.commits[2]},
+				{Name: "new-annotated", Commit: f.commits[3], Message: "Release notes"},
+				{Name: "release-202401010000-api", Commit: f.commits[2], Force: true}, // packed
+				{Name: "light-v1", Commit: f.commits[3], Force: true},                 // loose over packed
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tags, err := b.ListTags(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			byName := make(map[string]TagRef)
+			for _, tag := range tags {
+				byName[tag.Name] = tag
+			}
+			for name, want := range map[string]string{
+				"new-light":                f.commits[2],
+				"new-annotated":            f.commits[3],
+				"release-202401010000-api": f.commits[2],
+				"light-v1":                 f.commits[3],
+			} {
+				if byName[name].Commit != want {
+					t.Errorf("%s points at %s, want %s", name, byName[name].Commit, want)
+				}
+			}
+			if byName["new-light"].Object != byName["new-light"].Commit {
+				t.Error("new-light is annotated")
+			}
+			if info, err := b.TagInfo(ctx, "new-annotated"); err != nil || info.Message != "Release notes" || info.Tagger.Name != "Tester" {
+				t.Errorf("TagInfo(new-annotated) = %+v, %v", info, err)
+			}
+
+			if kind != "memory" {
+				runGit(t, f.dir, "fsck", "--strict", "--no-dangling")
+				if got := runGit(t, f.dir, "cat-file", "-t", "new-annotated"); got != "tag" {
+					t.Errorf("git sees new-annotated as a %s", got)
+				}
+			}
+		})
+	}
+}
+
+func TestBackendsCreateAndDeleteTags(t *testing.T) {
+	for _, kind := range backendKinds {
+		t.Run(kind, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture(t, true)
+			b := f.backend(t, kind)
+
+			if err := b.CreateTag(ctx, "created-light", "HEAD", TagOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.CreateTag(ctx, "created-annotated", f.commits[1], TagOptions{Message: "hello"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.CreateTag(ctx, "created-light", f.commits[0], TagOptions{}); err == nil {
+				t.Error("CreateTag() replaced an existing tag without force")
+			}
+			if err := b.CreateTag(ctx, "created-light", f.commits[0], TagOptions{Force: true}); err != nil {
+				t.Error(err)
+			}
+			if commit, _ := b.ResolveRef(ctx, "created-light"); commit != f.commits[0] {
+				t.Errorf("created-light points at %s after a forced CreateTag()", commit)
+			}
+
+			// Packed, loose over packed, and loose tags
+			deleted := []string{"release-202401010000-api", "light-v1", "created-annotated"}
+			if err := b.DeleteTags(ctx, deleted); err != nil {
+				t.Fatal(err)
+			}
+			if kind != "memory" {
+				b = f.backend(t, kind)
+			}
+			for _, name := range deleted {
+				if exists, err := b.RefExists(ctx, "refs/tags/"+name); err != nil || exists {
+					t.Errorf("RefExists(%s) after DeleteTags() = %v, %v", name, exists, err)
+				}
+			}
+			if exists, _ := b.RefExists(ctx, "refs/tags/release-202401020000-api"); !exists {
+				t.Error("DeleteTags() removed another packed tag")
+			}
+		})
+	}
+}
+
+func TestBackendsBlobRefs(t *testing.T) {
+	for _, kind := range backendKinds {
+		t.Run(kind, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture(t, true)
+			b := f.backend(t, kind)
+
+			if err := b.WriteBlobRef(ctx, YankedRefPrefix+"release-202401010000-api", "broken\n"); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.WriteBlobRef(ctx, YankedRefPrefix+"release-202401010000-api", "again\n"); err == nil {
+				t.Error("WriteBlobRef() replaced an existing ref")
+			}
+			yanked, err := Yanked(ctx, b)
+			if err != nil || !reflect.DeepEqual(yanked, map[string]string{"release-202401010000-api": "broken"}) {
+				t.Errorf("Yanked() = %v, %v", yanked, err)
+			}
+			if err := b.DeleteBlobRef(ctx, YankedRefPrefix+"release-202401010000-api"); err != nil {
+				t.Fatal(err)
+			}
+			if yanked, err := Yanked(ctx, b); err != nil || len(yanked) != 0 {
+				t.Errorf("Yanked() after DeleteBlobRef() = %v, %v", yanked, err)
+			}
+		})
+	}
+}
+
+func TestBackendsUpdateRemoteRef(t *testing.T) {
+	for _, kind := range backendKinds {
+		t.Run(kind, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture(t, true)
+			f.pushMain(t)
+			b := f.backend(t, kind)
+
+			const ref = "refs/rtag/lock"
+			remoteRef := func() string {
+				refs, err := b.RemoteRefs(ctx, "origin", ref)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return refs[ref]
+			}
+
+			if err := b.UpdateRemoteRef(ctx, "origin", ref, "", f.commits[0]); err != nil {
+				t.Fatal(err)
+			}
+			var pushErr *PushError
+			if err := b.UpdateRemoteRef(ctx, "origin", ref, "", f.commits[1]); !errors.As(err, &pushErr) {
+				t.Errorf("creating an existing ref: %v, want a *PushError", err)
+			}
+			if err := b.UpdateRemoteRef(ctx, "origin", ref, f.commits[2], f.commits[1]); !errors.As(err, &pushErr) {
+				t.Errorf("updating from a stale value: %v, want a *PushError", err)
+			}
+			if got := remoteRef(); got != f.commits[0] {
+				t.Errorf("a rejected update moved the ref to %s", got)
+			}
+			if err := b.UpdateRemoteRef(ctx, "origin", ref, f.commits[0], f.commits[1]); err != nil {
+				t.Fatal(err)
+			}
+			if got := remoteRef(); got != f.commits[1] {
+				t.Errorf("remote ref = %s, want %s", got, f.commits[1])
+			}
+			if err := b.UpdateRemoteRef(ctx, "origin", ref, f.commits[1], ""); err != nil {
+				t.Fatal(err)
+			}
+			if got := remoteRef(); got != "" {
+				t.Errorf("deleted remote ref still points at %s", got)
+			}
+
+			// Of concurrent creations of the same ref exactly one wins
+			const writers = 6
+			var wg sync.WaitGroup
+			errs := make([]error, writers)
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					writer := b
+					if kind != "memory" {
+						writer = f.backend(t, kind)
+					}
+					errs[i] = writer.UpdateRemoteRef(ctx, "origin", ref, "", f.commits[i%len(f.commits)])
+				}(i)
+			}
+			wg.Wait()
+
+			won := 0
+			for _, err := range errs {
+				if err == nil {
+					won++
+				}
+			}
+			if won != 1 {
+				t.Errorf("%d of %d concurrent creations succeeded: %v", won, writers, errs)
+			}
+		})
+	}
+}
+
+func TestNativeDeleteKeepsRefsWhenPackedRefsLocked(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, true)
+	lock := filepath.Join(f.dir, ".git", "packed-refs.lock")
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewNativeBackend(f.dir)
+	if err := b.DeleteTags(ctx, []string{"light-v1", "release-202401010000-api"}); err == nil {
+		t.Fatal("DeleteTags() succeeded while packed-refs was locked")
+	}
+
+	// The loose light-v1 must still hide its stale packed value
+	for name, want := range map[string]string{"light-v1": f.commits[1], "release-202401010000-api": f.commits[0]} {
+		if got := runGit(t, f.dir, "rev-parse", name+"^{commit}"); got != want {
+			t.Errorf("%s points at %s after a failed delete, want %s", name, got, want)
+		}
+	}
+}
+
+func TestNativeReleaseToNetworkRemote(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, true)
+	runGit(t, f.dir, "remote", "set-url", "origin", "https://example.com/repo.git")
+
+	b := NewNativeBackend(f.dir)
+	if err := b.CheckPush(ctx, "origin"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("CheckPush() = %v, want ErrUnsupported", err)
+	}
+
+	project, err := New(f.dir, WithBackend(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.Release(ctx, []string{"api"}, ReleaseOptions{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Release() = %v, want ErrUnsupported", err)
+	}
+	if tags := runGit(t, f.dir, "tag", "--list", "release-*-api"); strings.Count(tags, "\n") != 1 {
+		t.Errorf("a release that cannot be pushed created tags:\n%s", tags)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	f := newFixture(t, true)
+	write := func(name, content string) {
+		path := filepath.Join(f.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		change func()
+	}{
+		{"clean", func() {}},
+		{"modified", func() { write("notes.txt", "edited notes\n") }},
+		{"staged", func() { runGit(t, f.dir, "add", "notes.txt") }},
+		{"staged and modified", func() { write("notes.txt", "edited twice\n") }},
+		{"untracked", func() { write("new.txt", "new\n") }},
+		{"untracked directory", func() { write("build/out/a.txt", "a"); write("build/b.txt", "b") }},
+		{"ignored", func() {
+			write(".gitignore", "*.log\n/build/\n")
+			write("debug.log", "log")
+			write("src/trace.log", "log")
+		}},
+		{"negated", func() { write("src/.gitignore", "!keep.log\n"); write("src/keep.log", "keep") }},
+		{"deleted", func() { os.Remove(filepath.Join(f.dir, "data.txt")) }},
+		{"staged deletion", func() { runGit(t, f.dir, "rm", "-q", "--cached", "data.txt") }},
+		{"index version 4", func() { runGit(t, f.dir, "update-index", "--index-version", "4") }},
+		{"executable", func() { os.Chmod(filepath.Join(f.dir, "notes.txt"), 0755) }},
+		{"committed", func() { commitAt(t, f.dir, "fifth", "2024-01-05T10:00:00Z") }},
+	}
+
+	ctx := context.Background()
+	for _, step := range steps {
+		step.change()
+		want, err := NewExecBackend(f.dir).ChangedFiles(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := NewNativeBackend(f.dir).ChangedFiles(ctx)
+		if err != nil || got != want {
+			t.Errorf("%s: native ChangedFiles() = %d, %v, git status lists %d\n%s", step.name, got, err, want,
+				runGit(t, f.dir, "status", "--porcelain"))
+		}
+	}
+}
+
+func TestIgnoredMatchesGit(t *testing.T) {
+	setGitEnv(t)
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+
+	paths := []string{"a.log", ".log", "a.logs", "dir/a.log", "build", "a/build", "builds", "doc/a.txt",
+		"doc/sub/a.txt", "cache", "a/b/cache", "a/b", "a/x/b", "a/x/y/b", "ab", "x/a/b", "out/a", "out/a/b",
+		"file1.c", "filex.h", "file.c", "abc", "bcd", "#hash", "hash", "keep.log", "src/keep.log"}
+	for _, glob := range []string{"*.log", "build", "/build", "doc/*.txt", "**/cache", "a/**/b", "out/**",
+		"file?.[ch]", "[!a]*", `\#hash`, "*.log\n!keep.log", "*.log\n!/keep.log", "dir/\n!dir/a.log"} {
+		if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(glob+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		rules := readIgnoreFile(filepath.Join(dir, ".gitignore"), "")
+		for _, p := range paths {
+			want := exec.Command("git", "-C", dir, "check-ignore", "-q", "--no-index", p).Run() == nil
+			// countUntracked does not enter ignored directories
+			got := false
+			for i, c := range p {
+				if c == '/' && ignored(rules, p[:i], true) {
+					got = true
+				}
+			}
+			if got = got || ignored(rules, p, false); got != want {
+				t.Errorf("%q: ignored(%s) = %v, git says %v", glob, p, got, want)
+			}
+		}
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("0123456789abcdef")
+	for _, tc := range []struct {
+		name  string
+		delta []byte
+		want  string
+		err   bool
+	}{
+		{"insert", []byte{16, 3, 3, 'x', 'y', 'z'}, "xyz", false},
+		{"copy", []byte{16, 4, 0x91, 2, 4}, "2345", false},
+		{"copy and insert", []byte{16, 7, 0x90, 3, 4, 'w', 'x', 'y', 'z'}, "012wxyz", false},
+		{"copy with offset bytes", []byte{16, 2, 0x91, 14, 2}, "ef", false},
+		{"copy past the base", []byte{16, 4, 0x91, 14, 4}, "", true},
+		{"wrong result size", []byte{16, 5, 3, 'x', 'y', 'z'}, "", true},
+		{"truncated insert", []byte{16, 3, 3, 'x'}, "", true},
+		{"reserved opcode", []byte{16, 1, 0}, "", true},
+		{"truncated header", []byte{0x80}, "", true},
+	} {
+		got, err := applyDelta(base, tc.delta)
+		if (err != nil) != tc.err || string(got) != tc.want {
+			t.Errorf("%s: applyDelta() = %q, %v", tc.name, got, err)
+		}
+	}
+}
+
+func TestReadIndexMatchesGit(t *testing.T) {
+	f := newFixture(t, true)
+	for _, version := range []string{"2", "3", "4"} {
+		runGit(t, f.dir, "update-index", "--index-version", version)
+		entries, err := readIndex(filepath.Join(f.dir, ".git", "index"))
+		if err != nil {
+			t.Fatalf("version %s: %v", version, err)
+		}
+
+		var got []string
+		for _, entry := range entries {
+			got = append(got, entry.hash+" "+entry.path)
+		}
+		var want []string
+		for _, line := range strings.Split(runGit(t, f.dir, "ls-files", "-s"), "\n") {
+			fields := strings.Fields(line)
+			want = append(want, fields[1]+" "+fields[3])
+		}
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("version %s: readIndex() = %v, want %v", version, got, want)
+		}
+	}
+}
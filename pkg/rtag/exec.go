@@ -0,0 +1,419 @@
+package rtag
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// execBackend implements Backend by running the git binary
+type execBackend struct {
+	dir string
+}
+
+// NewExecBackend returns a backend running git in dir, or in the current
+// directory when dir is empty
+func NewExecBackend(dir string) Backend {
+	return &execBackend{dir: dir}
+}
+
+// run executes git and returns its standard output. Failures are reported as
+// *GitError carrying the exit code and standard error, along with whatever
+// was written to standard output. The process is killed when ctx is done,
+// and the context error is returned.
+func (b *execBackend) run(ctx context.Context, stdin string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = b.dir
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("git %s: %w", args[0], ctxErr)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return strings.TrimSpace(stdout.String()), &GitError{Op: args[0], ExitCode: exitErr.ExitCode(), Stderr: stderr.String()}
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (b *execBackend) Root(ctx context.Context) (string, error) {
+	return b.run(ctx, "", nil, "rev-parse", "--show-toplevel")
+}
+
+func (b *execBackend) Branch(ctx context.Context) (string, error) {
+	return b.run(ctx, "", nil, "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+func (b *execBackend) ChangedFiles(ctx context.Context) (int, error) {
+	output, err := b.run(ctx, "", nil, "status", "--porcelain")
+	if err != nil || output == "" {
+		return 0, err
+	}
+	return len(strings.Split(output, "\n")), nil
+}
+
+func (b *execBackend) Identity(ctx context.Context) (Signature, error) {
+	ident, err := b.run(ctx, "", nil, "var", "GIT_COMMITTER_IDENT")
+	if err != nil {
+		return Signature{}, err
+	}
+	return parseSignature(ident), nil
+}
+
+func (b *execBackend) ResolveRef(ctx context.Context, ref string) (string, error) {
+	commit, err := b.run(ctx, "", nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		return "", fmt.Errorf("%s: %w", ref, ErrNotFound)
+	}
+	return commit, err
+}
+
+func (b *execBackend) RefExists(ctx context.Context, ref string) (bool, error) {
+	_, err := b.run(ctx, "", nil, "show-ref", "--verify", "--quiet", ref)
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *execBackend) Log(ctx context.Context, since, until string) ([]CommitInfo, error) {
+	args := []string{"log", "--format=%H%x00%an%x00%ae%x00%at%x00%s%x00%b%x1e", until}
+	if since != "" {
+		args = append(args, "^"+since)
+	}
+
+	output, err := b.run(ctx, "", nil, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitInfo
+	// Commits are separated by \x1e since their bodies span several lines
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 6)
+		if len(fields) != 6 {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		commits = append(commits, CommitInfo{
+			Hash:    fields[0],
+			Author:  Signature{Name: fields[1], Email: fields[2], When: time.Unix(seconds, 0)},
+			Subject: fields[4],
+			Body:    strings.TrimSpace(fields[5]),
+		})
+	}
+	return commits, nil
+}
+
+func (b *execBackend) ListTags(ctx context.Context) ([]TagRef, error) {
+	output, err := b.run(ctx, "", nil, "for-each-ref", "--sort=creatordate", "--format=%(refname:strip=2) %(creatordate:unix) %(objectname) %(*objectname)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []TagRef
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// Annotated tags also report the peeled commit, lightweight tags point at the commit directly
+		ref := TagRef{Name: fields[0], Object: fields[2], Commit: fields[2], Date: time.Unix(seconds, 0)}
+		if len(fields) > 3 {
+			ref.Commit = fields[3]
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (b *execBackend) TagInfo(ctx context.Context, name string) (TagInfo, error) {
+	output, err := b.run(ctx, "", nil, "for-each-ref", "--format=%(taggername)%00%(taggeremail)%00%(taggerdate:unix)%00%(contents)", "refs/tags/"+name)
+	if err != nil {
+		return TagInfo{}, err
+	}
+
+	parts := strings.SplitN(output, "\x00", 4)
+	if len(parts) != 4 || parts[2] == "" {
+		return TagInfo{}, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+
+	seconds, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return TagInfo{}, err
+	}
+
+	return TagInfo{
+		Message: parts[3],
+		Tagger:  Signature{Name: parts[0], Email: strings.Trim(parts[1], "<>"), When: time.Unix(seconds, 0)},
+	}, nil
+}
+
+func (b *execBackend) CreateTag(ctx context.Context, name, target string, opts TagOptions) error {
+	commit, err := b.ResolveRef(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"tag"}
+	if opts.Force {
+		args = append(args, "-f")
+	}
+
+	if opts.Message == "" {
+		_, err := b.run(ctx, "", nil, append(args, name, commit)...)
+		return err
+	}
+
+	var env []string
+	if opts.Tagger != nil {
+		env = []string{
+			"GIT_COMMITTER_NAME=" + opts.Tagger.Name,
+			"GIT_COMMITTER_EMAIL=" + opts.Tagger.Email,
+			"GIT_COMMITTER_DATE=" + opts.Tagger.When.Format(time.RFC3339),
+		}
+	}
+
+	_, err = b.run(ctx, withNewline(opts.Message), env, append(args, "-a", "--cleanup=verbatim", "-F", "-", name, commit)...)
+	return err
+}
+
+func (b *execBackend) UpdateTags(ctx context.Context, updates []TagUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	// update-ref --stdin applies all the lines in one transaction. "create"
+	// fails if the ref exists, "update" without an old value overwrites it.
+	var input strings.Builder
+	var tagger string
+	for _, update := range updates {
+		object := update.Commit
+		if update.Message != "" {
+			if tagger == "" {
+				ident, err := b.run(ctx, "", nil, "var", "GIT_COMMITTER_IDENT")
+				if err != nil {
+					return err
+				}
+				tagger = ident
+			}
+
+			// The tag objects are written first, only the refs need the transaction
+			tag := fmt.Sprintf("object %s\ntype commit\ntag %s\ntagger %s\n\n%s", update.Commit, update.Name, tagger, withNewline(update.Message))
+			hash, err := b.run(ctx, tag, nil, "mktag")
+			if err != nil {
+				return err
+			}
+			object = hash
+		}
+
+		command := "create"
+		if update.Force {
+			command = "update"
+		}
+		fmt.Fprintf(&input, "%s refs/tags/%s %s\n", command, update.Name, object)
+	}
+
+	_, err := b.run(ctx, input.String(), nil, "update-ref", "--stdin")
+	return err
+}
+
+// withNewline terminates a tag message with a newline, as git does
+func withNewline(message string) string {
+	if !strings.HasSuffix(message, "\n") {
+		return message + "\n"
+	}
+	return message
+}
+
+func (b *execBackend) DeleteTags(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	_, err := b.run(ctx, "", nil, append([]string{"tag", "-d"}, names...)...)
+	return err
+}
+
+func (b *execBackend) DeleteBlobRef(ctx context.Context, ref string) error {
+	_, err := b.run(ctx, "", nil, "update-ref", "-d", ref)
+	return err
+}
+
+func (b *execBackend) WriteBlobRef(ctx context.Context, ref, content string) error {
+	blob, err := b.run(ctx, content, nil, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return err
+	}
+
+	// The empty old value makes the update fail if the ref already exists
+	_, err = b.run(ctx, "", nil, "update-ref", ref, blob, "")
+	return err
+}
+
+func (b *execBackend) WriteBlob(ctx context.Context, content string) (string, error) {
+	return b.run(ctx, content, nil, "hash-object", "-w", "--stdin")
+}
+
+func (b *execBackend) ReadBlob(ctx context.Context, hash string) (string, error) {
+	return b.run(ctx, "", nil, "cat-file", "blob", hash)
+}
+
+func (b *execBackend) ReadBlobRefs(ctx context.Context, prefix string) (map[string]string, error) {
+	output, err := b.run(ctx, "", nil, "for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string]string)
+	for _, ref := range strings.Fields(output) {
+		content, err := b.run(ctx, "", nil, "cat-file", "blob", ref)
+		if err != nil {
+			return nil, err
+		}
+		contents[strings.TrimPrefix(ref, prefix)] = content
+	}
+	return contents, nil
+}
+
+func (b *execBackend) PushRefs(ctx context.Context, remote string, refspecs []string) error {
+	output, err := b.run(ctx, "", nil, append([]string{"push", "--porcelain", remote}, refspecs...)...)
+	return pushError(refspecs, output, err)
+}
+
+// CheckPush accepts every remote, git reports the failures of the push itself
+func (b *execBackend) CheckPush(ctx context.Context, remote string) error {
+	return nil
+}
+
+func (b *execBackend) UpdateRemoteRef(ctx context.Context, remote, ref, old, hash string) error {
+	// The lease rejects the push unless the remote ref is old, an empty old
+	// meaning that it must not exist. A "+" refspec would skip that check.
+	refspec := hash + ":" + ref
+	if hash == "" {
+		refspec = ":" + ref
+	}
+
+	output, err := b.run(ctx, "", nil, "push", "--porcelain", "--force-with-lease="+ref+":"+old, remote, refspec)
+	return pushError([]string{refspec}, output, err)
+}
+
+func (b *execBackend) RemoteURL(ctx context.Context, remote string) (string, error) {
+	url, err := b.run(ctx, "", nil, "remote", "get-url", remote)
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 2 {
+		return "", fmt.Errorf("%s: %w", remote, ErrNotFound)
+	}
+	return url, err
+}
+
+func (b *execBackend) RemoteRefs(ctx context.Context, remote, prefix string) (map[string]string, error) {
+	output, err := b.run(ctx, "", nil, "ls-remote", remote, prefix+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		hash, name, found := strings.Cut(line, "\t")
+		if found && strings.HasPrefix(name, prefix) {
+			refs[name] = hash
+		}
+	}
+	return refs, nil
+}
+
+func (b *execBackend) FetchRefs(ctx context.Context, remote string, refs []string) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	_, err := b.run(ctx, "", nil, append([]string{"fetch", "--quiet", "--no-tags", remote}, refs...)...)
+	return err
+}
+
+// pushError turns the outcome of git push --porcelain into the error of
+// PushRefs: a *PushError when the remote refused some of the refspecs
+func pushError(refspecs []string, output string, err error) error {
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) {
+		return err
+	}
+
+	// Map the destination refs back to the refspecs they were pushed with
+	byDestination := make(map[string]string, len(refspecs))
+	for _, refspec := range refspecs {
+		src, dst, found := strings.Cut(strings.TrimPrefix(refspec, "+"), ":")
+		if !found {
+			dst = src
+		}
+		byDestination[dst] = refspec
+	}
+
+	// The porcelain output has a "flag<TAB>src:dst<TAB>summary (reason)" line
+	// per ref, and the flag "!" marks the refs that were not updated
+	var rejected []RefRejection
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || fields[0] != "!" {
+			continue
+		}
+		_, dst, _ := strings.Cut(fields[1], ":")
+		refspec, ok := byDestination[dst]
+		if !ok {
+			continue
+		}
+		rejected = append(rejected, RefRejection{Refspec: refspec, Reason: rejectionReason(fields[2], dst, gitErr.Stderr)})
+	}
+
+	// Without per-ref results the push failed as a whole, e.g. the remote was unreachable
+	if len(rejected) == 0 {
+		return err
+	}
+	return &PushError{Rejected: rejected, Err: err}
+}
+
+// rejectionReason extracts the reason from a porcelain summary such as
+// "[rejected] (already exists)". The remote only reports "failed to update
+// ref" for a ref it could not lock, the cause is in its error output.
+func rejectionReason(summary, ref, stderr string) string {
+	reason := summary
+	if start := strings.Index(summary, "("); start >= 0 {
+		reason = strings.TrimSuffix(summary[start+1:], ")")
+	}
+
+	if strings.Contains(stderr, "cannot lock ref '"+ref+"'") {
+		return "cannot lock ref"
+	}
+	return reason
+}
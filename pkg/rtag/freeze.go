@@ -0,0 +1,202 @@
+package rtag
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Freeze is a period during which releases are blocked, either a date range
+// or a window recurring every week
+type Freeze struct {
+	Name       string   `json:"name"`
+	Start      string   `json:"start"`      // "2006-01-02" or "2006-01-02 15:04"
+	End        string   `json:"end"`        // same formats, a date alone includes the whole day
+	Weekly     string   `json:"weekly"`     // recurring window instead of a range, e.g. "fri 18:00 - mon 08:00"
+	Components []string `json:"components"` // frozen components, patterns allowed, all of them when empty
+	Timezone   string   `json:"timezone"`   // zone of the dates and times, local time by default
+}
+
+// RuleFreezes names the freezes setting in a *PolicyError
+const RuleFreezes PolicyRule = "freezes"
+
+// Label returns the name of the freeze, or its window when it has none
+func (f Freeze) Label() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	if f.Weekly != "" {
+		return f.Weekly
+	}
+	return f.Start + " - " + f.End
+}
+
+// Applies reports whether the freeze blocks the release of component
+func (f Freeze) Applies(component string) bool {
+	return len(f.Components) == 0 || MatchAny(f.Components, component)
+}
+
+// FreezeWindow is one occurrence of a freeze, the end is excluded
+type FreezeWindow struct {
+	Freeze Freeze
+	Start  time.Time
+	End    time.Time
+}
+
+// Active reports whether releases are blocked at now
+func (w FreezeWindow) Active(now time.Time) bool {
+	return !now.Before(w.Start) && now.Before(w.End)
+}
+
+// FreezeWindows returns the occurrences of freezes that are active at now or
+// start within horizon, ordered by start
+func FreezeWindows(freezes []Freeze, now time.Time, horizon time.Duration) ([]FreezeWindow, error) {
+	until := now.Add(horizon)
+
+	var windows []FreezeWindow
+	for _, freeze := range freezes {
+		occurrences, err := freeze.windows(now, until)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, occurrences...)
+	}
+
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+	return windows, nil
+}
+
+// ActiveFreezes returns the freeze windows blocking the release of one of
+// components at now
+func ActiveFreezes(freezes []Freeze, components []string, now time.Time) ([]FreezeWindow, error) {
+	windows, err := FreezeWindows(freezes, now, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []FreezeWindow
+	for _, window := range windows {
+		if !window.Active(now) {
+			continue
+		}
+		for _, component := range components {
+			if window.Freeze.Applies(component) {
+				active = append(active, window)
+				break
+			}
+		}
+	}
+	return active, nil
+}
+
+// windows returns the occurrences of the freeze that end after from and start before until
+func (f Freeze) windows(from, until time.Time) ([]FreezeWindow, error) {
+	location := time.Local
+	if f.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(f.Timezone); err != nil {
+			return nil, &PolicyError{Rule: RuleFreezes, Value: f.Timezone}
+		}
+	}
+
+	if f.Weekly != "" {
+		if f.Start != "" || f.End != "" {
+			return nil, &PolicyError{Rule: RuleFreezes, Value: f.Label()}
+		}
+		return f.weeklyWindows(from, until, location)
+	}
+
+	start, _, err := parseFreezeTime(f.Start, location)
+	if err != nil {
+		return nil, err
+	}
+	end, dateOnly, err := parseFreezeTime(f.End, location)
+	if err != nil {
+		return nil, err
+	}
+	if dateOnly {
+		end = end.AddDate(0, 0, 1)
+	}
+	if !start.Before(end) {
+		return nil, &PolicyError{Rule: RuleFreezes, Value: f.Label()}
+	}
+
+	if !end.After(from) || start.After(until) {
+		return nil, nil
+	}
+	return []FreezeWindow{{Freeze: f, Start: start, End: end}}, nil
+}
+
+// weeklyWindows returns the occurrences of a weekly freeze
+func (f Freeze) weeklyWindows(from, until time.Time, location *time.Location) ([]FreezeWindow, error) {
+	first, last, found := strings.Cut(f.Weekly, "-")
+	startDay, startMinute, err1 := parseWeekTime(first)
+	endDay, endMinute, err2 := parseWeekTime(last)
+	if !found || err1 != nil || err2 != nil {
+		return nil, &PolicyError{Rule: RuleFreezes, Value: f.Weekly}
+	}
+
+	// The window may wrap around the end of the week, e.g. fri - mon
+	const week = 7 * 24 * 60
+	length := ((endDay*24*60 + endMinute) - (startDay*24*60 + startMinute) + week) % week
+	if length == 0 {
+		return nil, &PolicyError{Rule: RuleFreezes, Value: f.Weekly}
+	}
+
+	// Start from the latest occurrence beginning before from, which may
+	// still be running
+	local := from.In(location)
+	days := (int(local.Weekday()) - startDay + 7) % 7
+	start := time.Date(local.Year(), local.Month(), local.Day()-days, 0, startMinute, 0, 0, location)
+	if start.After(local) {
+		start = start.AddDate(0, 0, -7)
+	}
+
+	var windows []FreezeWindow
+	for ; !start.After(until); start = start.AddDate(0, 0, 7) {
+		end := time.Date(start.Year(), start.Month(), start.Day(), 0, startMinute+length, 0, 0, location)
+		if end.After(from) {
+			windows = append(windows, FreezeWindow{Freeze: f, Start: start, End: end})
+		}
+	}
+	return windows, nil
+}
+
+// parseFreezeTime parses a freeze date with an optional time and reports whether the time was omitted
+func parseFreezeTime(value string, location *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation(time.DateOnly, value, location); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, location); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, &PolicyError{Rule: RuleFreezes, Value: value}
+}
+
+// parseWeekTime parses a weekday and time such as "fri 18:00" into the day,
+// sunday being 0, and the minutes since midnight
+func parseWeekTime(value string) (int, int, error) {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) != 2 {
+		return 0, 0, &PolicyError{Rule: RuleFreezes, Value: value}
+	}
+
+	day := -1
+	for i, weekday := range weekdays {
+		if fields[0] == weekday {
+			day = i
+		}
+	}
+
+	hour, minute, found := strings.Cut(fields[1], ":")
+	h, err1 := strconv.Atoi(hour)
+	m, err2 := strconv.Atoi(minute)
+	if day < 0 || !found || err1 != nil || err2 != nil || h < 0 || h > 24 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, 0, &PolicyError{Rule: RuleFreezes, Value: value}
+	}
+	return day, h*60 + m, nil
+}
@@ -0,0 +1,197 @@
+package rtag
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// loadLocation returns a time zone, skipping the test when the zone database is missing
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s: %v", name, err)
+	}
+	return location
+}
+
+func TestActiveFreezesWeekly(t *testing.T) {
+	paris := loadLocation(t, "Europe/Paris")
+	newYork := loadLocation(t, "America/New_York")
+	weekend := Freeze{Name: "weekend", Weekly: "fri 18:00 - mon 08:00", Timezone: "Europe/Paris"}
+	saturdayNight := Freeze{Weekly: "sat 22:00 - sun 02:00", Timezone: "Europe/Paris"}
+	midweek := Freeze{Weekly: "Tue 09:30 - wed 24:00", Timezone: "Europe/Paris"}
+
+	// May 3, 2024 is a friday; Paris leaves summer time on sunday October 27
+	tests := []struct {
+		name   string
+		freeze Freeze
+		now    time.Time
+		want   bool
+	}{
+		{"before the start", weekend, time.Date(2024, 5, 3, 17, 59, 0, 0, paris), false},
+		{"at the start", weekend, time.Date(2024, 5, 3, 18, 0, 0, 0, paris), true},
+		{"saturday", weekend, time.Date(2024, 5, 4, 12, 0, 0, 0, paris), true},
+		{"sunday before midnight", weekend, time.Date(2024, 5, 5, 23, 59, 59, 0, paris), true},
+		{"sunday at midnight", weekend, time.Date(2024, 5, 5, 0, 0, 0, 0, paris), true},
+		{"just before the end", weekend, time.Date(2024, 5, 6, 7, 59, 59, 0, paris), true},
+		{"at the end", weekend, time.Date(2024, 5, 6, 8, 0, 0, 0, paris), false},
+		{"midweek", weekend, time.Date(2024, 5, 8, 12, 0, 0, 0, paris), false},
+		{"next weekend", weekend, time.Date(2024, 5, 11, 9, 0, 0, 0, paris), true},
+
+		// The window is in the zone of the freeze, whatever the zone of now
+		{"started in Paris, UTC", weekend, time.Date(2024, 5, 3, 16, 0, 0, 0, time.UTC), true},
+		{"not started in Paris, UTC", weekend, time.Date(2024, 5, 3, 15, 59, 0, 0, time.UTC), false},
+		{"started in Paris, New York", weekend, time.Date(2024, 5, 3, 12, 0, 0, 0, newYork), true},
+		{"monday in New York, ended in Paris", weekend, time.Date(2024, 5, 6, 2, 0, 0, 0, newYork), false},
+		{"sunday in New York, monday in Paris", weekend, time.Date(2024, 5, 5, 20, 0, 0, 0, newYork), true},
+
+		// Wall clock times are kept across daylight saving changes
+		{"end of summer time, before the end", weekend, time.Date(2024, 10, 28, 7, 59, 0, 0, paris), true},
+		{"end of summer time, at the end", weekend, time.Date(2024, 10, 28, 8, 0, 0, 0, paris), false},
+		{"end of summer time, at the start", weekend, time.Date(2024, 10, 25, 18, 0, 0, 0, paris), true},
+
+		// Saturday to sunday wraps from the last to the first day of the week
+		{"wrap, saturday", saturdayNight, time.Date(2024, 5, 4, 23, 0, 0, 0, paris), true},
+		{"wrap, sunday", saturdayNight, time.Date(2024, 5, 5, 1, 59, 0, 0, paris), true},
+		{"wrap, sunday at the end", saturdayNight, time.Date(2024, 5, 5, 2, 0, 0, 0, paris), false},
+		{"wrap, saturday before", saturdayNight, time.Date(2024, 5, 4, 21, 59, 0, 0, paris), false},
+
+		// 24:00 ends the window at the following midnight
+		{"midnight end, wednesday", midweek, time.Date(2024, 5, 8, 23, 59, 0, 0, paris), true},
+		{"midnight end, thursday", midweek, time.Date(2024, 5, 9, 0, 0, 0, 0, paris), false},
+		{"capitalized day", midweek, time.Date(2024, 5, 7, 9, 30, 0, 0, paris), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := ActiveFreezes([]Freeze{tt.freeze}, []string{"api"}, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(active) == 1; got != tt.want {
+				t.Errorf("ActiveFreezes(%s at %v) = %+v, want active %v", tt.freeze.Weekly, tt.now, active, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveFreezesRange(t *testing.T) {
+	paris := loadLocation(t, "Europe/Paris")
+
+	tests := []struct {
+		name   string
+		freeze Freeze
+		now    time.Time
+		want   bool
+	}{
+		{"before a day", Freeze{Start: "2024-12-24", End: "2024-12-26"}, time.Date(2024, 12, 23, 23, 59, 0, 0, time.Local), false},
+		{"start day", Freeze{Start: "2024-12-24", End: "2024-12-26"}, time.Date(2024, 12, 24, 0, 0, 0, 0, time.Local), true},
+		{"whole end day", Freeze{Start: "2024-12-24", End: "2024-12-26"}, time.Date(2024, 12, 26, 23, 59, 0, 0, time.Local), true},
+		{"after the end day", Freeze{Start: "2024-12-24", End: "2024-12-26"}, time.Date(2024, 12, 27, 0, 0, 0, 0, time.Local), false},
+		{"end time excluded", Freeze{Start: "2024-12-24 12:00", End: "2024-12-26 08:00"}, time.Date(2024, 12, 26, 8, 0, 0, 0, time.Local), false},
+		{"start time included", Freeze{Start: "2024-12-24 12:00", End: "2024-12-26 08:00"}, time.Date(2024, 12, 24, 12, 0, 0, 0, time.Local), true},
+		{"time zone", Freeze{Start: "2024-12-24 12:00", End: "2024-12-26 08:00", Timezone: "Europe/Paris"}, time.Date(2024, 12, 24, 11, 0, 0, 0, time.UTC), true},
+		{"before in the time zone", Freeze{Start: "2024-12-24 12:00", End: "2024-12-26 08:00", Timezone: "Europe/Paris"}, time.Date(2024, 12, 24, 11, 59, 0, 0, paris), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := ActiveFreezes([]Freeze{tt.freeze}, []string{"api"}, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(active) == 1; got != tt.want {
+				t.Errorf("ActiveFreezes(%s at %v) = %+v, want active %v", tt.freeze.Label(), tt.now, active, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveFreezesComponents(t *testing.T) {
+	freeze := Freeze{Weekly: "tue 00:00 - thu 00:00", Components: []string{"api", "web-*"}}
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		components []string
+		want       bool
+	}{
+		{[]string{"api"}, true},
+		{[]string{"web-admin"}, true},
+		{[]string{"cron", "web-shop"}, true},
+		{[]string{"cron"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		active, err := ActiveFreezes([]Freeze{freeze}, tt.components, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(active) == 1; got != tt.want {
+			t.Errorf("ActiveFreezes(%v) = %+v, want active %v", tt.components, active, tt.want)
+		}
+	}
+}
+
+func TestFreezeWindowsUpcoming(t *testing.T) {
+	paris := loadLocation(t, "Europe/Paris")
+	freezes := []Freeze{
+		{Name: "weekend", Weekly: "fri 18:00 - mon 08:00", Timezone: "Europe/Paris"},
+		{Name: "holidays", Start: "2024-05-09", End: "2024-05-09", Timezone: "Europe/Paris"},
+		{Name: "later", Start: "2024-06-01", End: "2024-06-02", Timezone: "Europe/Paris"},
+	}
+
+	// On wednesday May 8, the next 10 days hold the holiday and two weekends
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, paris)
+	windows, err := FreezeWindows(freezes, now, 10*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"holidays", time.Date(2024, 5, 9, 0, 0, 0, 0, paris), time.Date(2024, 5, 10, 0, 0, 0, 0, paris)},
+		{"weekend", time.Date(2024, 5, 10, 18, 0, 0, 0, paris), time.Date(2024, 5, 13, 8, 0, 0, 0, paris)},
+		{"weekend", time.Date(2024, 5, 17, 18, 0, 0, 0, paris), time.Date(2024, 5, 20, 8, 0, 0, 0, paris)},
+	}
+	if len(windows) != len(want) {
+		t.Fatalf("FreezeWindows() = %+v, want %d windows", windows, len(want))
+	}
+	for i, w := range want {
+		got := windows[i]
+		if got.Freeze.Name != w.name || !got.Start.Equal(w.start) || !got.End.Equal(w.end) {
+			t.Errorf("window %d = %s %v - %v, want %s %v - %v", i, got.Freeze.Name, got.Start, got.End, w.name, w.start, w.end)
+		}
+	}
+}
+
+func TestFreezeWindowsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		freeze Freeze
+	}{
+		{"no separator", Freeze{Weekly: "fri 18:00 mon 08:00"}},
+		{"unknown day", Freeze{Weekly: "fry 18:00 - mon 08:00"}},
+		{"hour out of range", Freeze{Weekly: "fri 25:00 - mon 08:00"}},
+		{"after midnight", Freeze{Weekly: "fri 24:30 - mon 08:00"}},
+		{"empty window", Freeze{Weekly: "fri 18:00 - fri 18:00"}},
+		{"weekly with dates", Freeze{Weekly: "fri 18:00 - mon 08:00", Start: "2024-05-03"}},
+		{"unknown time zone", Freeze{Weekly: "fri 18:00 - mon 08:00", Timezone: "Mars/Olympus"}},
+		{"end before start", Freeze{Start: "2024-05-03", End: "2024-05-02"}},
+		{"bad date", Freeze{Start: "03/05/2024", End: "2024-05-06"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FreezeWindows([]Freeze{tt.freeze}, time.Now(), 0)
+			var policyErr *PolicyError
+			if !errors.As(err, &policyErr) || policyErr.Rule != RuleFreezes || !errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("FreezeWindows(%+v) error = %v, want an invalid freeze", tt.freeze, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package rtag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LockRefPrefix is the ref namespace of release locks on the remote. Each
+// locked component has a ref pointing at a blob describing the lock.
+const LockRefPrefix = "refs/rtag-locks/"
+
+// ErrLocked is wrapped by *LockError
+var ErrLocked = errors.New("component is being released by someone else")
+
+// ReleaseLock is held on the remote while a component is released
+type ReleaseLock struct {
+	Component string
+	Holder    string
+	Acquired  time.Time
+	Expires   time.Time
+	object    string // the lock blob, which identifies this acquisition
+}
+
+// Expired reports whether the lock can be taken over
+func (l ReleaseLock) Expired(now time.Time) bool {
+	return !now.Before(l.Expires)
+}
+
+// LockError reports a component locked by another holder
+type LockError struct {
+	Lock ReleaseLock
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("%s: locked by %s until %s", e.Lock.Component, e.Lock.Holder, e.Lock.Expires.Format(time.RFC3339))
+}
+
+func (e *LockError) Unwrap() error {
+	return ErrLocked
+}
+
+// Locks returns the release locks on the remote, expired ones included,
+// ordered by component
+func (p *Project) Locks(ctx context.Context) ([]ReleaseLock, error) {
+	refs, err := p.backend.RemoteRefs(ctx, p.remote, LockRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if err := p.backend.FetchRefs(ctx, p.remote, names); err != nil {
+		return nil, err
+	}
+
+	locks := make([]ReleaseLock, 0, len(names))
+	for _, name := range names {
+		lock, err := p.readLock(ctx, strings.TrimPrefix(name, LockRefPrefix), refs[name])
+		if err != nil {
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// Lock acquires the release locks of components on the remote for ttl.
+// Expired locks are taken over. If a component is locked by someone else,
+// the locks acquired so far are released and a *LockError is returned.
+func (p *Project) Lock(ctx context.Context, components []string, holder string, ttl time.Duration) ([]ReleaseLock, error) {
+	refs, err := p.backend.RemoteRefs(ctx, p.remote, LockRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Locking in a fixed order keeps two overlapping releases from each
+	// holding a part of the other's components
+	sorted := append([]string(nil), components...)
+	sort.Strings(sorted)
+
+	var locks []ReleaseLock
+	for i, component := range sorted {
+		if i > 0 && component == sorted[i-1] {
+			continue
+		}
+
+		lock, err := p.lock(ctx, component, refs[LockRefPrefix+component], holder, ttl)
+		if err != nil {
+			p.Unlock(context.WithoutCancel(ctx), locks)
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// lock acquires the lock of one component, current is the lock blob on the remote or ""
+func (p *Project) lock(ctx context.Context, component, current, holder string, ttl time.Duration) (ReleaseLock, error) {
+	now := time.Now()
+	if current != "" {
+		if err := p.backend.FetchRefs(ctx, p.remote, []string{LockRefPrefix + component}); err != nil {
+			return ReleaseLock{}, err
+		}
+		existing, err := p.readLock(ctx, component, current)
+		if err != nil {
+			return ReleaseLock{}, err
+		}
+		if !existing.Expired(now) {
+			return ReleaseLock{}, &LockError{Lock: existing}
+		}
+	}
+
+	lock := ReleaseLock{Component: component, Holder: holder, Acquired: now, Expires: now.Add(ttl)}
+	content := fmt.Sprintf("component %s\nholder %s\nacquired %s\nexpires %s\n",
+		component, holder, lock.Acquired.UTC().Format(time.RFC3339Nano), lock.Expires.UTC().Format(time.RFC3339Nano))
+	object, err := p.backend.WriteBlob(ctx, content)
+	if err != nil {
+		return ReleaseLock{}, err
+	}
+
+	// The remote refuses the update if another release took the lock since
+	// it was read, report that release instead
+	if err := p.backend.UpdateRemoteRef(ctx, p.remote, LockRefPrefix+component, current, object); err != nil {
+		var pushErr *PushError
+		if errors.As(err, &pushErr) {
+			if locks, err := p.Locks(ctx); err == nil {
+				for _, other := range locks {
+					if other.Component == component {
+						return ReleaseLock{}, &LockError{Lock: other}
+					}
+				}
+			}
+		}
+		return ReleaseLock{}, err
+	}
+
+	lock.object = object
+	return lock, nil
+}
+
+// Unlock releases locks acquired by Lock. A lock that expired and was taken
+// over by another holder is left alone.
+func (p *Project) Unlock(ctx context.Context, locks []ReleaseLock) error {
+	var errs []error
+	for _, lock := range locks {
+		err := p.backend.UpdateRemoteRef(ctx, p.remote, LockRefPrefix+lock.Component, lock.object, "")
+		var pushErr *PushError
+		if err != nil && !errors.As(err, &pushErr) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BreakLock removes the lock of a component, whoever holds it
+func (p *Project) BreakLock(ctx context.Context, component string) error {
+	ref := LockRefPrefix + component
+	refs, err := p.backend.RemoteRefs(ctx, p.remote, ref)
+	if err != nil {
+		return err
+	}
+
+	current, ok := refs[ref]
+	if !ok {
+		return fmt.Errorf("%s: %w", component, ErrNotFound)
+	}
+	return p.backend.UpdateRemoteRef(ctx, p.remote, ref, current, "")
+}
+
+// readLock parses a lock blob. A lock that cannot be parsed has expired, so
+// it does not block releases forever.
+func (p *Project) readLock(ctx context.Context, component, object string) (ReleaseLock, error) {
+	content, err := p.backend.ReadBlob(ctx, object)
+	if err != nil {
+		return ReleaseLock{}, err
+	}
+
+	lock := ReleaseLock{Component: component, object: object}
+	for _, line := range strings.Split(content, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "holder":
+			lock.Holder = value
+		case "acquired":
+			lock.Acquired, _ = time.Parse(time.RFC3339Nano, value)
+		case "expires":
+			lock.Expires, _ = time.Parse(time.RFC3339Nano, value)
+		}
+	}
+	return lock, nil
+}
@@ -0,0 +1,162 @@
+package rtag
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryBackend is a git repository held in memory. It starts with an empty
+// main branch; Commit adds history and AddRemote creates remotes to push to.
+type MemoryBackend struct {
+	objectBackend
+	repo *memoryStorage
+}
+
+// NewMemoryBackend returns an empty in-memory repository rooted at root
+func NewMemoryBackend(root string) *MemoryBackend {
+	repo := &memoryStorage{
+		root:     root,
+		headRef:  "refs/heads/main",
+		refMap:   make(map[string]string),
+		objects:  make(map[string]memoryObject),
+		settings: map[string]string{"user.name": "rtag", "user.email": "rtag@localhost"},
+		remotes:  make(map[string]*memoryStorage),
+	}
+	return &MemoryBackend{objectBackend: objectBackend{storage: repo}, repo: repo}
+}
+
+// Commit records a commit with an empty tree on the current branch and returns its hash
+func (m *MemoryBackend) Commit(message string, when time.Time) (string, error) {
+	tree, err := m.store("tree", nil)
+	if err != nil {
+		return "", err
+	}
+
+	author := formatSignature(Signature{Name: m.repo.settings["user.name"], Email: m.repo.settings["user.email"], When: when})
+	data := "tree " + tree + "\n"
+	if parent, ok := m.repo.refMap[m.repo.headRef]; ok {
+		data += "parent " + parent + "\n"
+	}
+	data += fmt.Sprintf("author %s\ncommitter %s\n\n%s\n", author, author, message)
+
+	commit, err := m.store("commit", []byte(data))
+	if err != nil {
+		return "", err
+	}
+	return commit, m.repo.setRef(m.repo.headRef, commit, false)
+}
+
+// AddRemote creates an empty remote repository and returns it
+func (m *MemoryBackend) AddRemote(name string) *MemoryBackend {
+	remote := NewMemoryBackend("")
+	m.repo.remotes[name] = remote.repo
+	return remote
+}
+
+// memoryObject is a stored object
+type memoryObject struct {
+	kind string
+	data []byte
+}
+
+// memoryStorage is the objectStorage of a MemoryBackend
+type memoryStorage struct {
+	root     string
+	headRef  string
+	refMap   map[string]string
+	objects  map[string]memoryObject
+	settings map[string]string
+	remotes  map[string]*memoryStorage
+	changed  int
+
+	mu sync.Mutex // guards refMap, so that concurrent ref updates are atomic
+}
+
+func (s *memoryStorage) workTree() (string, error) {
+	if s.root == "" {
+		return "", fmt.Errorf("%w: no working tree", ErrNotRepository)
+	}
+	return s.root, nil
+}
+
+func (s *memoryStorage) head() (string, error) {
+	return "ref: " + s.headRef, nil
+}
+
+func (s *memoryStorage) changedFiles() (int, error) {
+	return s.changed, nil
+}
+
+func (s *memoryStorage) refs() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refs := make(map[string]string, len(s.refMap))
+	for name, hash := range s.refMap {
+		refs[name] = hash
+	}
+	return refs, nil
+}
+
+func (s *memoryStorage) setRef(name, hash string, create bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.refMap[name]; exists && create {
+		return fmt.Errorf("%s already exists", name)
+	}
+	s.refMap[name] = hash
+	return nil
+}
+
+func (s *memoryStorage) deleteRefs(names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		delete(s.refMap, name)
+	}
+	return nil
+}
+
+func (s *memoryStorage) swapRef(name, old, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refMap[name] != old {
+		return errStaleRef
+	}
+	if hash == "" {
+		delete(s.refMap, name)
+	} else {
+		s.refMap[name] = hash
+	}
+	return nil
+}
+
+func (s *memoryStorage) readObject(hash string) (string, []byte, error) {
+	obj, ok := s.objects[hash]
+	if !ok {
+		return "", nil, fmt.Errorf("object %s: %w", hash, ErrNotFound)
+	}
+	return obj.kind, obj.data, nil
+}
+
+func (s *memoryStorage) hasObject(hash string) bool {
+	_, ok := s.objects[hash]
+	return ok
+}
+
+func (s *memoryStorage) writeObject(hash, kind string, data []byte) error {
+	s.objects[hash] = memoryObject{kind: kind, data: append([]byte(nil), data...)}
+	return nil
+}
+
+func (s *memoryStorage) config(key string) string {
+	return s.settings[key]
+}
+
+func (s *memoryStorage) remote(name string) (objectStorage, error) {
+	remote, ok := s.remotes[name]
+	if !ok {
+		return nil, fmt.Errorf("remote %s: %w", name, ErrNotFound)
+	}
+	return remote, nil
+}
@@ -0,0 +1,121 @@
+package rtag
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultTemplate describes the release tags created by push
+const DefaultTemplate = "release-{timestamp}-{component}"
+
+// TimestampFormat is the layout of {timestamp} in tag names (YYYYMMDDHHMM)
+const TimestampFormat = "200601021504"
+
+// templateFields maps each supported placeholder to the pattern it matches
+var templateFields = map[string]string{
+	"component": `(.+?)`,
+	"timestamp": `(\d{12})`,
+	"version":   `(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)`,
+}
+
+// Template is a tag naming scheme such as release-{timestamp}-{component}
+// or {component}-v{version}
+type Template struct {
+	pattern string
+	fields  []string
+	regexp  *regexp.Regexp
+}
+
+// ParseTemplate compiles a tag template. Every template must contain {component}.
+func ParseTemplate(pattern string) (Template, error) {
+	var expr strings.Builder
+	var fields []string
+	expr.WriteString("^")
+
+	rest := pattern
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			expr.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return Template{}, &NameError{Name: pattern, Err: ErrInvalidTemplate}
+		}
+
+		field := rest[start+1 : start+end]
+		fieldPattern, ok := templateFields[field]
+		if !ok {
+			return Template{}, &NameError{Name: pattern, Field: field, Err: ErrUnknownField}
+		}
+		for _, f := range fields {
+			if f == field {
+				return Template{}, &NameError{Name: pattern, Err: ErrInvalidTemplate}
+			}
+		}
+
+		expr.WriteString(regexp.QuoteMeta(rest[:start]))
+		expr.WriteString(fieldPattern)
+		fields = append(fields, field)
+		rest = rest[start+end+1:]
+	}
+	expr.WriteString("$")
+
+	template := Template{pattern: pattern, fields: fields, regexp: regexp.MustCompile(expr.String())}
+	if !template.Has("component") {
+		return Template{}, &NameError{Name: pattern, Field: "component", Err: ErrMissingField}
+	}
+	return template, nil
+}
+
+// String returns the template pattern
+func (t Template) String() string {
+	return t.pattern
+}
+
+// Fields returns the placeholders of the template in order
+func (t Template) Fields() []string {
+	return t.fields
+}
+
+// Has reports whether the template contains a placeholder
+func (t Template) Has(field string) bool {
+	for _, f := range t.fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Match extracts the placeholder values from a tag name
+func (t Template) Match(name string) (map[string]string, bool) {
+	groups := t.regexp.FindStringSubmatch(name)
+	if groups == nil {
+		return nil, false
+	}
+
+	values := make(map[string]string, len(t.fields))
+	for i, field := range t.fields {
+		values[field] = groups[i+1]
+	}
+	return values, true
+}
+
+// FloatingName returns the moving tag that always points at the latest
+// release of a component, the template with {timestamp} replaced by "latest",
+// e.g. release-latest-{component}
+func (t Template) FloatingName(component string) string {
+	return t.Format(map[string]string{"component": component, "timestamp": "latest"})
+}
+
+// Format builds a tag name from placeholder values
+func (t Template) Format(values map[string]string) string {
+	name := t.pattern
+	for _, field := range t.fields {
+		name = strings.ReplaceAll(name, "{"+field+"}", values[field])
+	}
+	return name
+}
@@ -0,0 +1,722 @@
+package rtag
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotRepository is returned when no git repository is found
+var ErrNotRepository = errors.New("not a git repository")
+
+// NewNativeBackend returns a backend that reads and writes the repository
+// containing dir without the git binary. Pushing is limited to remotes that
+// are local paths or file:// URLs.
+func NewNativeBackend(dir string) Backend {
+	return &objectBackend{storage: &fileStorage{dir: dir, search: true}}
+}
+
+// fileStorage is the objectStorage of a .git directory. It is opened on first use.
+type fileStorage struct {
+	dir    string // directory the repository is looked up from
+	search bool   // also look in the parent directories of dir
+
+	opened    bool
+	err       error
+	gitDir    string // HEAD and per-worktree files
+	commonDir string // objects, refs and config, shared by linked worktrees
+	workDir   string // "" for bare repositories
+	packs     []*packIndex
+	settings  map[string]string
+}
+
+// open locates the git directory. A .git file, as used by linked worktrees
+// and submodules, points to the real git directory.
+func (s *fileStorage) open() error {
+	if s.opened {
+		return s.err
+	}
+	s.opened = true
+
+	dir, err := filepath.Abs(s.dir)
+	if err != nil {
+		s.err = err
+		return err
+	}
+
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			s.workDir = dir
+			if info.IsDir() {
+				s.gitDir = dotGit
+			} else if s.gitDir, err = readGitFile(dotGit); err != nil {
+				s.err = err
+				return err
+			}
+			break
+		}
+
+		if isGitDir(dir) {
+			s.gitDir = dir
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if !s.search || parent == dir {
+			s.err = fmt.Errorf("%s: %w", s.dir, ErrNotRepository)
+			return s.err
+		}
+		dir = parent
+	}
+
+	s.commonDir = s.gitDir
+	if data, err := os.ReadFile(filepath.Join(s.gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(s.gitDir, common)
+		}
+		s.commonDir = filepath.Clean(common)
+	}
+
+	s.settings = readGitConfig(s.commonDir)
+	return nil
+}
+
+// readGitFile returns the git directory a "gitdir: <path>" file points to
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !found {
+		return "", fmt.Errorf("%s: %w", path, ErrNotRepository)
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(path), dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// isGitDir reports whether dir looks like a bare repository
+func isGitDir(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// readGitConfig reads the global and repository config files. Keys are
+// lowercased section and name with the subsection kept as is, such as
+// "remote.origin.url". Later files override earlier ones.
+func readGitConfig(commonDir string) map[string]string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		xdg := os.Getenv("XDG_CONFIG_HOME")
+		if xdg == "" {
+			xdg = filepath.Join(home, ".config")
+		}
+		paths = append(paths, filepath.Join(xdg, "git", "config"), filepath.Join(home, ".gitconfig"))
+	}
+	paths = append(paths, filepath.Join(commonDir, "config"))
+
+	settings := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		section := ""
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || line[0] == '#' || line[0] == ';' {
+				continue
+			}
+
+			if line[0] == '[' {
+				header := line[1:strings.Index(line+"]", "]")]
+				name, sub, found := strings.Cut(header, " ")
+				section = strings.ToLower(name)
+				if found {
+					section += "." + strings.Trim(strings.TrimSpace(sub), `"`)
+				}
+				continue
+			}
+
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				value = "true"
+			}
+			settings[section+"."+strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return settings
+}
+
+func (s *fileStorage) workTree() (string, error) {
+	if err := s.open(); err != nil {
+		return "", err
+	}
+	if s.workDir == "" {
+		return "", fmt.Errorf("%s: bare repository has no working tree", s.gitDir)
+	}
+	return s.workDir, nil
+}
+
+func (s *fileStorage) head() (string, error) {
+	if err := s.open(); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(s.gitDir, "HEAD"))
+	return strings.TrimSpace(string(data)), err
+}
+
+// refs reads packed-refs and then the loose refs, which take precedence
+func (s *fileStorage) refs() (map[string]string, error) {
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	refs, err := s.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(s.commonDir, "refs")
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasSuffix(path, ".lock") {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		value := strings.TrimSpace(string(data))
+		if isObjectID(value) {
+			rel, _ := filepath.Rel(s.commonDir, path)
+			refs[filepath.ToSlash(rel)] = value
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func (s *fileStorage) packedRefs() (map[string]string, error) {
+	refs := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(s.commonDir, "packed-refs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return refs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		// Skip the header and the ^peeled lines following annotated tags
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		if hash, name, found := strings.Cut(line, " "); found {
+			refs[name] = hash
+		}
+	}
+	return refs, nil
+}
+
+// lockFile creates path.lock exclusively so concurrent writers fail instead of racing
+func lockFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path+".lock", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+}
+
+// commitLock writes data to a lock file and renames it over path
+func commitLock(lock *os.File, path string, data []byte) error {
+	if _, err := lock.Write(data); err != nil {
+		lock.Close()
+		os.Remove(lock.Name())
+		return err
+	}
+	if err := lock.Close(); err != nil {
+		os.Remove(lock.Name())
+		return err
+	}
+	return os.Rename(lock.Name(), path)
+}
+
+func (s *fileStorage) setRef(name, hash string, create bool) error {
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.commonDir, filepath.FromSlash(name))
+	lock, err := lockFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot lock ref %s: %w", name, err)
+	}
+
+	if create {
+		refs, err := s.refs()
+		if err == nil && refs[name] != "" {
+			err = fmt.Errorf("%s already exists", name)
+		}
+		if err != nil {
+			lock.Close()
+			os.Remove(lock.Name())
+			return err
+		}
+	}
+
+	return commitLock(lock, path, []byte(hash+"\n"))
+}
+
+// swapRef holds the lock of the ref while it checks and updates it, so
+// concurrent writers of the same ref fail instead of racing
+func (s *fileStorage) swapRef(name, old, hash string) error {
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.commonDir, filepath.FromSlash(name))
+	lock, err := lockFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot lock ref %s: %w", name, err)
+	}
+
+	refs, err := s.refs()
+	if err == nil && refs[name] != old {
+		err = errStaleRef
+	}
+	if err == nil && hash == "" {
+		err = s.deleteRefs([]string{name})
+	}
+	if err != nil || hash == "" {
+		lock.Close()
+		os.Remove(lock.Name())
+		return err
+	}
+	return commitLock(lock, path, []byte(hash+"\n"))
+}
+
+// deleteRefs removes refs from packed-refs and then their loose files. The
+// packed-refs lock is taken first: a loose ref removed while its packed
+// entry stays would bring back the stale packed value.
+func (s *fileStorage) deleteRefs(names []string) error {
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.commonDir, "packed-refs")
+	lock, err := lockFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot lock packed-refs: %w", err)
+	}
+
+	deleted := make(map[string]bool)
+	for _, name := range names {
+		deleted[name] = true
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		lock.Close()
+		os.Remove(lock.Name())
+		return err
+	}
+
+	var kept bytes.Buffer
+	skipping, changed := false, false
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.HasPrefix(line, "^") {
+			if !skipping {
+				kept.WriteString(line)
+			}
+			continue
+		}
+		_, name, _ := strings.Cut(strings.TrimSpace(line), " ")
+		skipping = deleted[name]
+		if skipping {
+			changed = true
+		} else {
+			kept.WriteString(line)
+		}
+	}
+
+	if changed {
+		err = commitLock(lock, path, kept.Bytes())
+	} else {
+		lock.Close()
+		err = os.Remove(lock.Name())
+	}
+	if err != nil {
+		return err
+	}
+
+	for name := range deleted {
+		err := os.Remove(filepath.Join(s.commonDir, filepath.FromSlash(name)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fileStorage) objectPath(hash string) string {
+	return filepath.Join(s.commonDir, "objects", hash[:2], hash[2:])
+}
+
+func (s *fileStorage) hasObject(hash string) bool {
+	if s.open() != nil || !isObjectID(hash) {
+		return false
+	}
+	if _, err := os.Stat(s.objectPath(hash)); err == nil {
+		return true
+	}
+	for _, pack := range s.packIndexes() {
+		if _, ok := pack.find(hash); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *fileStorage) readObject(hash string) (string, []byte, error) {
+	if err := s.open(); err != nil {
+		return "", nil, err
+	}
+	if !isObjectID(hash) {
+		return "", nil, fmt.Errorf("%s: %w", hash, ErrNotFound)
+	}
+
+	if f, err := os.Open(s.objectPath(hash)); err == nil {
+		defer f.Close()
+		return readLooseObject(f)
+	}
+
+	for _, pack := range s.packIndexes() {
+		if offset, ok := pack.find(hash); ok {
+			return s.readPackedObject(pack, offset)
+		}
+	}
+	return "", nil, fmt.Errorf("object %s: %w", hash, ErrNotFound)
+}
+
+// readLooseObject inflates a loose object and splits off its "<type> <size>\0" header
+func readLooseObject(r io.Reader) (string, []byte, error) {
+	z, err := zlib.NewReader(r)
+	if err != nil {
+		return "", nil, err
+	}
+	defer z.Close()
+
+	data, err := io.ReadAll(z)
+	if err != nil {
+		return "", nil, err
+	}
+
+	header, content, found := bytes.Cut(data, []byte{0})
+	if !found {
+		return "", nil, errors.New("corrupt loose object")
+	}
+	kind, _, _ := strings.Cut(string(header), " ")
+	return kind, content, nil
+}
+
+// writeObject writes a loose object through a temporary file so readers never see it half written
+func (s *fileStorage) writeObject(hash, kind string, data []byte) error {
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	path := s.objectPath(hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp_obj_")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	z := zlib.NewWriter(tmp)
+	fmt.Fprintf(z, "%s %d\x00", kind, len(data))
+	z.Write(data)
+	if err := z.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *fileStorage) config(key string) string {
+	if s.open() != nil {
+		return ""
+	}
+	return s.settings[key]
+}
+
+// remote opens a remote that is a local path or file:// URL
+func (s *fileStorage) remote(name string) (objectStorage, error) {
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	url := s.settings["remote."+name+".url"]
+	if url == "" {
+		url = name
+	}
+
+	path, isFile := strings.CutPrefix(url, "file://")
+	if !isFile && (strings.Contains(url, "://") || strings.Contains(strings.Split(url, "/")[0], ":")) {
+		return nil, fmt.Errorf("push to %s: the native backend only pushes to local repositories, install git for other remotes: %w", url, ErrUnsupported)
+	}
+
+	if !filepath.IsAbs(path) {
+		base := s.workDir
+		if base == "" {
+			base = s.gitDir
+		}
+		path = filepath.Join(base, path)
+	}
+
+	remote := &fileStorage{dir: path}
+	if err := remote.open(); err != nil {
+		return nil, err
+	}
+	return remote, nil
+}
+
+// packIndex is a version 2 pack index together with the path of its pack
+type packIndex struct {
+	pack string
+	data []byte
+	size int
+}
+
+func (s *fileStorage) packIndexes() []*packIndex {
+	if s.packs != nil {
+		return s.packs
+	}
+
+	s.packs = []*packIndex{}
+	paths, _ := filepath.Glob(filepath.Join(s.commonDir, "objects", "pack", "*.idx"))
+	sort.Strings(paths)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil || len(data) < 8+256*4 || !bytes.Equal(data[:8], []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}) {
+			continue
+		}
+		s.packs = append(s.packs, &packIndex{
+			pack: strings.TrimSuffix(path, ".idx") + ".pack",
+			data: data,
+			size: int(binary.BigEndian.Uint32(data[8+255*4:])),
+		})
+	}
+	return s.packs
+}
+
+// find returns the pack offset of an object
+func (p *packIndex) find(hash string) (int64, bool) {
+	id, err := hex.DecodeString(hash)
+	if err != nil {
+		return 0, false
+	}
+
+	fanout := p.data[8:]
+	lo := 0
+	if id[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(fanout[(int(id[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(fanout[int(id[0])*4:]))
+
+	names := p.data[8+256*4:]
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(names[(lo+i)*20:(lo+i)*20+20], id) >= 0
+	})
+	if i >= hi || !bytes.Equal(names[i*20:i*20+20], id) {
+		return 0, false
+	}
+
+	offsets := p.data[8+256*4+p.size*24:]
+	offset := int64(binary.BigEndian.Uint32(offsets[i*4:]))
+	if offset&0x80000000 != 0 {
+		large := offsets[p.size*4:]
+		offset = int64(binary.BigEndian.Uint64(large[(offset&0x7fffffff)*8:]))
+	}
+	return offset, true
+}
+
+var packObjectTypes = map[byte]string{1: "commit", 2: "tree", 3: "blob", 4: "tag"}
+
+func (s *fileStorage) readPackedObject(pack *packIndex, offset int64) (string, []byte, error) {
+	f, err := os.Open(pack.pack)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	return s.readPackEntry(f, offset)
+}
+
+// readPackEntry reads the object at offset, resolving deltas against their base objects
+func (s *fileStorage) readPackEntry(f *os.File, offset int64) (string, []byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return "", nil, err
+	}
+	r := bufio.NewReader(io.NewSectionReader(f, offset, info.Size()-offset))
+
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+	kind := (c >> 4) & 7
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var baseKind string
+	var base []byte
+	switch kind {
+	case 6: // delta against an earlier object of the same pack
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+		distance := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return "", nil, err
+			}
+			distance = (distance+1)<<7 | int64(c&0x7f)
+		}
+		if baseKind, base, err = s.readPackEntry(f, offset-distance); err != nil {
+			return "", nil, err
+		}
+	case 7: // delta against an object given by hash
+		id := make([]byte, 20)
+		if _, err := io.ReadFull(r, id); err != nil {
+			return "", nil, err
+		}
+		if baseKind, base, err = s.readObject(hex.EncodeToString(id)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	z, err := zlib.NewReader(r)
+	if err != nil {
+		return "", nil, err
+	}
+	defer z.Close()
+	data, err := io.ReadAll(z)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if base == nil {
+		name, ok := packObjectTypes[kind]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown pack object type %d", kind)
+		}
+		return name, data, nil
+	}
+
+	data, err = applyDelta(base, data)
+	return baseKind, data, err
+}
+
+// applyDelta rebuilds an object from its base and a pack delta
+func applyDelta(base, delta []byte) ([]byte, error) {
+	corrupt := errors.New("corrupt pack delta")
+	varint := func() (int, bool) {
+		n, shift := 0, 0
+		for len(delta) > 0 {
+			c := delta[0]
+			delta = delta[1:]
+			n |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return n, true
+			}
+		}
+		return 0, false
+	}
+
+	if _, ok := varint(); !ok {
+		return nil, corrupt
+	}
+	size, ok := varint()
+	if !ok {
+		return nil, corrupt
+	}
+
+	out := make([]byte, 0, size)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		if op&0x80 == 0 {
+			if op == 0 || int(op) > len(delta) {
+				return nil, corrupt
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+			continue
+		}
+
+		// Copy from the base: the low bits say which offset bytes follow, the high bits which size bytes
+		var offset, length int
+		for i := 0; i < 7; i++ {
+			if op&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, corrupt
+			}
+			if i < 4 {
+				offset |= int(delta[0]) << (8 * i)
+			} else {
+				length |= int(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if length == 0 {
+			length = 0x10000
+		}
+		if offset+length > len(base) {
+			return nil, corrupt
+		}
+		out = append(out, base[offset:offset+length]...)
+	}
+
+	if len(out) != size {
+		return nil, corrupt
+	}
+	return out, nil
+}
@@ -0,0 +1,790 @@
+package rtag
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// objectStorage is where a repository keeps its objects, refs and settings.
+// The native backend stores them in a .git directory, the memory backend in maps.
+type objectStorage interface {
+	// workTree returns the top-level directory of the working tree
+	workTree() (string, error)
+	// head returns the content of HEAD, either "ref: <name>" or a commit hash
+	head() (string, error)
+	// changedFiles returns the number of paths with uncommitted changes
+	changedFiles() (int, error)
+
+	// refs returns all refs mapped to the object they point to
+	refs() (map[string]string, error)
+	// setRef points a ref at hash. With create set it fails if the ref exists.
+	setRef(name, hash string, create bool) error
+	// deleteRefs removes refs
+	deleteRefs(names []string) error
+	// swapRef points a ref at hash, or deletes it when hash is empty,
+	// provided that it currently points at old, "" meaning that it must not
+	// exist. The check and the update are atomic.
+	swapRef(name, old, hash string) error
+
+	// readObject returns the type and content of an object
+	readObject(hash string) (string, []byte, error)
+	// hasObject reports whether an object is stored
+	hasObject(hash string) bool
+	// writeObject stores an object under its hash
+	writeObject(hash, kind string, data []byte) error
+
+	// config returns a setting such as user.name, or ""
+	config(key string) string
+	// remote opens the storage of a remote repository
+	remote(name string) (objectStorage, error)
+}
+
+// errStaleRef is returned by swapRef when the ref no longer points at the expected object
+var errStaleRef = errors.New("stale info")
+
+// objectBackend implements Backend on top of an objectStorage by reading and
+// writing git objects itself
+type objectBackend struct {
+	storage objectStorage
+}
+
+// gitObject is a parsed commit or tag: its headers in order and its message
+type gitObject struct {
+	headers [][2]string
+	message string
+}
+
+// header returns the first value of a header, or ""
+func (o gitObject) header(name string) string {
+	for _, h := range o.headers {
+		if h[0] == name {
+			return h[1]
+		}
+	}
+	return ""
+}
+
+// parseObject splits a commit or tag object into headers and message
+func parseObject(data []byte) gitObject {
+	var obj gitObject
+	text := string(data)
+	for text != "" {
+		line, rest, _ := strings.Cut(text, "\n")
+		text = rest
+		if line == "" {
+			break
+		}
+		// Continuation lines such as in gpgsig belong to the previous header
+		if strings.HasPrefix(line, " ") && len(obj.headers) > 0 {
+			obj.headers[len(obj.headers)-1][1] += "\n" + line[1:]
+			continue
+		}
+		name, value, _ := strings.Cut(line, " ")
+		obj.headers = append(obj.headers, [2]string{name, value})
+	}
+	obj.message = text
+	return obj
+}
+
+// parseSignature parses "Name <email> 1700000000 +0800"
+func parseSignature(value string) Signature {
+	var sig Signature
+	name, rest, found := strings.Cut(value, " <")
+	if !found {
+		return sig
+	}
+	sig.Name = name
+
+	email, rest, _ := strings.Cut(rest, "> ")
+	sig.Email = email
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sig
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return sig
+	}
+	sig.When = time.Unix(seconds, 0)
+
+	if len(fields) > 1 && len(fields[1]) == 5 {
+		hours, _ := strconv.Atoi(fields[1][1:3])
+		minutes, _ := strconv.Atoi(fields[1][3:])
+		offset := hours*3600 + minutes*60
+		if fields[1][0] == '-' {
+			offset = -offset
+		}
+		sig.When = sig.When.In(time.FixedZone(fields[1], offset))
+	}
+	return sig
+}
+
+// formatSignature formats a signature as it appears in commit and tag headers
+func formatSignature(sig Signature) string {
+	return fmt.Sprintf("%s <%s> %d %s", sig.Name, sig.Email, sig.When.Unix(), sig.When.Format("-0700"))
+}
+
+// hashObject returns the object id of content of the given type
+func hashObject(kind string, data []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s %d\x00", kind, len(data))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isObjectID reports whether s is a full hexadecimal object id
+func isObjectID(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func (b *objectBackend) store(kind string, data []byte) (string, error) {
+	hash := hashObject(kind, data)
+	if b.storage.hasObject(hash) {
+		return hash, nil
+	}
+	return hash, b.storage.writeObject(hash, kind, data)
+}
+
+func (b *objectBackend) Identity(ctx context.Context) (Signature, error) {
+	return b.identity()
+}
+
+// identity returns the signature used for new tags
+func (b *objectBackend) identity() (Signature, error) {
+	name := os.Getenv("GIT_COMMITTER_NAME")
+	if name == "" {
+		name = b.storage.config("user.name")
+	}
+	email := os.Getenv("GIT_COMMITTER_EMAIL")
+	if email == "" {
+		email = b.storage.config("user.email")
+	}
+	if name == "" || email == "" {
+		return Signature{}, &GitError{Op: "tag", ExitCode: 128, Stderr: "committer identity unknown: set user.name and user.email"}
+	}
+	return Signature{Name: name, Email: email, When: time.Now()}, nil
+}
+
+// lookupRef returns the object a ref points to, trying the same prefixes as git rev-parse
+func (b *objectBackend) lookupRef(ref string) (string, error) {
+	if isObjectID(ref) && b.storage.hasObject(ref) {
+		return ref, nil
+	}
+
+	refs, err := b.storage.refs()
+	if err != nil {
+		return "", err
+	}
+
+	if ref == "HEAD" {
+		head, err := b.storage.head()
+		if err != nil {
+			return "", err
+		}
+		target, symbolic := strings.CutPrefix(head, "ref: ")
+		if !symbolic {
+			return head, nil
+		}
+		ref = target
+	}
+
+	for _, candidate := range []string{ref, "refs/" + ref, "refs/tags/" + ref, "refs/heads/" + ref, "refs/remotes/" + ref} {
+		if hash, ok := refs[candidate]; ok {
+			return hash, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w", ref, ErrNotFound)
+}
+
+// peel follows tag objects until it reaches an object of another type
+func (b *objectBackend) peel(hash string) (string, string, error) {
+	for {
+		kind, data, err := b.storage.readObject(hash)
+		if err != nil {
+			return "", "", err
+		}
+		if kind != "tag" {
+			return hash, kind, nil
+		}
+		hash = parseObject(data).header("object")
+	}
+}
+
+func (b *objectBackend) readCommit(hash string) (gitObject, error) {
+	kind, data, err := b.storage.readObject(hash)
+	if err != nil {
+		return gitObject{}, err
+	}
+	if kind != "commit" {
+		return gitObject{}, fmt.Errorf("%s is a %s, not a commit", hash, kind)
+	}
+	return parseObject(data), nil
+}
+
+func (b *objectBackend) Root(ctx context.Context) (string, error) {
+	return b.storage.workTree()
+}
+
+func (b *objectBackend) Branch(ctx context.Context) (string, error) {
+	head, err := b.storage.head()
+	if err != nil {
+		return "", err
+	}
+	if branch, ok := strings.CutPrefix(head, "ref: refs/heads/"); ok {
+		return branch, nil
+	}
+	return "HEAD", nil
+}
+
+func (b *objectBackend) ChangedFiles(ctx context.Context) (int, error) {
+	return b.storage.changedFiles()
+}
+
+func (b *objectBackend) ResolveRef(ctx context.Context, ref string) (string, error) {
+	hash, err := b.lookupRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	commit, kind, err := b.peel(hash)
+	if err != nil {
+		return "", err
+	}
+	if kind != "commit" {
+		return "", fmt.Errorf("%s: %w", ref, ErrNotFound)
+	}
+	return commit, nil
+}
+
+func (b *objectBackend) RefExists(ctx context.Context, ref string) (bool, error) {
+	refs, err := b.storage.refs()
+	if err != nil {
+		return false, err
+	}
+	_, ok := refs[ref]
+	return ok, nil
+}
+
+// queuedCommit is a commit waiting to be visited by Log
+type queuedCommit struct {
+	hash      string
+	commit    gitObject
+	committed time.Time
+}
+
+// commitQueue orders commits from newest to oldest committer date
+type commitQueue []queuedCommit
+
+func (q commitQueue) Len() int            { return len(q) }
+func (q commitQueue) Less(i, j int) bool  { return q[i].committed.After(q[j].committed) }
+func (q commitQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x interface{}) { *q = append(*q, x.(queuedCommit)) }
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+func (b *objectBackend) Log(ctx context.Context, since, until string) ([]CommitInfo, error) {
+	excluded := make(map[string]bool)
+	if since != "" {
+		start, err := b.ResolveRef(ctx, since)
+		if err != nil {
+			return nil, err
+		}
+		pending := []string{start}
+		for len(pending) > 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			hash := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if excluded[hash] {
+				continue
+			}
+			excluded[hash] = true
+
+			commit, err := b.readCommit(hash)
+			if err != nil {
+				return nil, err
+			}
+			for _, h := range commit.headers {
+				if h[0] == "parent" {
+					pending = append(pending, h[1])
+				}
+			}
+		}
+	}
+
+	start, err := b.ResolveRef(ctx, until)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitInfo
+	seen := map[string]bool{start: true}
+	queue := &commitQueue{}
+	push := func(hash string) error {
+		commit, err := b.readCommit(hash)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, queuedCommit{hash: hash, commit: commit, committed: parseSignature(commit.header("committer")).When})
+		return nil
+	}
+	if !excluded[start] {
+		if err := push(start); err != nil {
+			return nil, err
+		}
+	}
+
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		next := heap.Pop(queue).(queuedCommit)
+		subject, body, _ := strings.Cut(strings.TrimLeft(next.commit.message, "\n"), "\n")
+		commits = append(commits, CommitInfo{
+			Hash:    next.hash,
+			Author:  parseSignature(next.commit.header("author")),
+			Subject: subject,
+			Body:    strings.TrimSpace(body),
+		})
+
+		for _, h := range next.commit.headers {
+			if h[0] != "parent" || seen[h[1]] || excluded[h[1]] {
+				continue
+			}
+			seen[h[1]] = true
+			if err := push(h[1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return commits, nil
+}
+
+func (b *objectBackend) ListTags(ctx context.Context) ([]TagRef, error) {
+	refs, err := b.storage.refs()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []TagRef
+	for name, hash := range refs {
+		tag, found := strings.CutPrefix(name, "refs/tags/")
+		if !found {
+			continue
+		}
+
+		kind, data, err := b.storage.readObject(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		ref := TagRef{Name: tag, Object: hash, Commit: hash}
+		switch kind {
+		case "tag":
+			ref.Date = parseSignature(parseObject(data).header("tagger")).When
+			if ref.Commit, _, err = b.peel(hash); err != nil {
+				return nil, err
+			}
+		case "commit":
+			ref.Date = parseSignature(parseObject(data).header("committer")).When
+		}
+		tags = append(tags, ref)
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if !tags[i].Date.Equal(tags[j].Date) {
+			return tags[i].Date.Before(tags[j].Date)
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (b *objectBackend) TagInfo(ctx context.Context, name string) (TagInfo, error) {
+	hash, err := b.lookupRef("refs/tags/" + name)
+	if err != nil {
+		return TagInfo{}, err
+	}
+
+	kind, data, err := b.storage.readObject(hash)
+	if err != nil {
+		return TagInfo{}, err
+	}
+	if kind != "tag" {
+		return TagInfo{}, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+
+	tag := parseObject(data)
+	return TagInfo{
+		Message: strings.TrimRight(tag.message, "\n"),
+		Tagger:  parseSignature(tag.header("tagger")),
+	}, nil
+}
+
+func (b *objectBackend) CreateTag(ctx context.Context, name, target string, opts TagOptions) error {
+	commit, err := b.ResolveRef(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	hash := commit
+	if opts.Message != "" {
+		if hash, err = b.storeTag(name, commit, opts.Message, opts.Tagger); err != nil {
+			return err
+		}
+	}
+
+	if err := b.storage.setRef("refs/tags/"+name, hash, !opts.Force); err != nil {
+		return &GitError{Op: "tag", ExitCode: 128, Stderr: err.Error()}
+	}
+	return nil
+}
+
+// storeTag writes an annotated tag object, tagged by the configured identity when tagger is nil
+func (b *objectBackend) storeTag(name, commit, message string, tagger *Signature) (string, error) {
+	if tagger == nil {
+		sig, err := b.identity()
+		if err != nil {
+			return "", err
+		}
+		tagger = &sig
+	}
+
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	data := fmt.Sprintf("object %s\ntype commit\ntag %s\ntagger %s\n\n%s", commit, name, formatSignature(*tagger), message)
+	return b.store("tag", []byte(data))
+}
+
+func (b *objectBackend) UpdateTags(ctx context.Context, updates []TagUpdate) error {
+	refs, err := b.storage.refs()
+	if err != nil {
+		return err
+	}
+
+	// Check every update before writing so a bad one leaves the refs untouched
+	for _, update := range updates {
+		if !isObjectID(update.Commit) || !b.storage.hasObject(update.Commit) {
+			return &GitError{Op: "update-ref", ExitCode: 128, Stderr: fmt.Sprintf("%s: not a valid object", update.Commit)}
+		}
+		if _, ok := refs["refs/tags/"+update.Name]; ok && !update.Force {
+			return &GitError{Op: "update-ref", ExitCode: 128, Stderr: fmt.Sprintf("cannot lock ref 'refs/tags/%s': reference already exists", update.Name)}
+		}
+	}
+
+	for i, update := range updates {
+		if err := ctx.Err(); err != nil {
+			b.restoreTags(updates[:i], refs)
+			return err
+		}
+		object := update.Commit
+		if update.Message != "" {
+			hash, err := b.storeTag(update.Name, update.Commit, update.Message, nil)
+			if err != nil {
+				b.restoreTags(updates[:i], refs)
+				return err
+			}
+			object = hash
+		}
+		if err := b.storage.setRef("refs/tags/"+update.Name, object, !update.Force); err != nil {
+			b.restoreTags(updates[:i], refs)
+			return &GitError{Op: "update-ref", ExitCode: 128, Stderr: err.Error()}
+		}
+	}
+	return nil
+}
+
+// restoreTags undoes applied updates, putting back the refs as they were before
+func (b *objectBackend) restoreTags(applied []TagUpdate, before map[string]string) {
+	var created []string
+	for _, update := range applied {
+		ref := "refs/tags/" + update.Name
+		if old, ok := before[ref]; ok {
+			b.storage.setRef(ref, old, false)
+		} else {
+			created = append(created, ref)
+		}
+	}
+	if len(created) > 0 {
+		b.storage.deleteRefs(created)
+	}
+}
+
+func (b *objectBackend) DeleteTags(ctx context.Context, names []string) error {
+	refs := make([]string, len(names))
+	for i, name := range names {
+		refs[i] = "refs/tags/" + name
+	}
+	return b.storage.deleteRefs(refs)
+}
+
+func (b *objectBackend) DeleteBlobRef(ctx context.Context, ref string) error {
+	return b.storage.deleteRefs([]string{ref})
+}
+
+func (b *objectBackend) WriteBlobRef(ctx context.Context, ref, content string) error {
+	hash, err := b.store("blob", []byte(content))
+	if err != nil {
+		return err
+	}
+	return b.storage.setRef(ref, hash, true)
+}
+
+func (b *objectBackend) WriteBlob(ctx context.Context, content string) (string, error) {
+	return b.store("blob", []byte(content))
+}
+
+func (b *objectBackend) ReadBlob(ctx context.Context, hash string) (string, error) {
+	kind, data, err := b.storage.readObject(hash)
+	if err != nil {
+		return "", err
+	}
+	if kind != "blob" {
+		return "", &GitError{Op: "cat-file", ExitCode: 128, Stderr: fmt.Sprintf("%s is a %s, not a blob", hash, kind)}
+	}
+	return string(data), nil
+}
+
+func (b *objectBackend) ReadBlobRefs(ctx context.Context, prefix string) (map[string]string, error) {
+	refs, err := b.storage.refs()
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string]string)
+	for name, hash := range refs {
+		key, found := strings.CutPrefix(name, prefix)
+		if !found {
+			continue
+		}
+		_, data, err := b.storage.readObject(hash)
+		if err != nil {
+			return nil, err
+		}
+		contents[key] = string(data)
+	}
+	return contents, nil
+}
+
+// CheckPush opens the remote, which fails for the remotes the storage cannot reach
+func (b *objectBackend) CheckPush(ctx context.Context, remote string) error {
+	_, err := b.storage.remote(remote)
+	return err
+}
+
+// PushRefs copies the objects behind each refspec to the remote and updates
+// its refs. Like git push, existing refs are only replaced by force refspecs
+// and the other refs are still pushed when one is rejected.
+func (b *objectBackend) PushRefs(ctx context.Context, remote string, refspecs []string) error {
+	dst, err := b.storage.remote(remote)
+	if err != nil {
+		return err
+	}
+
+	local, err := b.storage.refs()
+	if err != nil {
+		return err
+	}
+	existing, err := dst.refs()
+	if err != nil {
+		return err
+	}
+
+	var rejected []RefRejection
+	for _, refspec := range refspecs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		spec, force := strings.CutPrefix(refspec, "+")
+		src, name, found := strings.Cut(spec, ":")
+		if !found {
+			name = src
+		}
+
+		if src == "" {
+			if err := dst.deleteRefs([]string{name}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		hash, ok := local[src]
+		if !ok {
+			return &GitError{Op: "push", ExitCode: 1, Stderr: fmt.Sprintf("src refspec %s does not match any", src)}
+		}
+
+		if old, ok := existing[name]; ok && old != hash && !force {
+			rejected = append(rejected, RefRejection{Refspec: refspec, Reason: "already exists"})
+			continue
+		}
+
+		if err := copyObjects(ctx, b.storage, dst, hash); err != nil {
+			return err
+		}
+		// Like git, a ref that cannot be updated does not stop the others
+		if err := dst.setRef(name, hash, false); err != nil {
+			rejected = append(rejected, RefRejection{Refspec: refspec, Reason: err.Error()})
+		}
+	}
+
+	if len(rejected) > 0 {
+		return &PushError{Rejected: rejected}
+	}
+	return nil
+}
+
+func (b *objectBackend) UpdateRemoteRef(ctx context.Context, remote, ref, old, hash string) error {
+	dst, err := b.storage.remote(remote)
+	if err != nil {
+		return err
+	}
+
+	refspec := hash + ":" + ref
+	if hash == "" {
+		refspec = ":" + ref
+	} else if err := copyObjects(ctx, b.storage, dst, hash); err != nil {
+		return err
+	}
+
+	if err := dst.swapRef(ref, old, hash); err != nil {
+		return &PushError{Rejected: []RefRejection{{Refspec: refspec, Reason: err.Error()}}}
+	}
+	return nil
+}
+
+func (b *objectBackend) RemoteURL(ctx context.Context, remote string) (string, error) {
+	url := b.storage.config("remote." + remote + ".url")
+	if url == "" {
+		return "", fmt.Errorf("%s: %w", remote, ErrNotFound)
+	}
+	return url, nil
+}
+
+func (b *objectBackend) RemoteRefs(ctx context.Context, remote, prefix string) (map[string]string, error) {
+	src, err := b.storage.remote(remote)
+	if err != nil {
+		return nil, err
+	}
+	all, err := src.refs()
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]string)
+	for name, hash := range all {
+		if strings.HasPrefix(name, prefix) {
+			refs[name] = hash
+		}
+	}
+	return refs, nil
+}
+
+func (b *objectBackend) FetchRefs(ctx context.Context, remote string, refs []string) error {
+	src, err := b.storage.remote(remote)
+	if err != nil {
+		return err
+	}
+	all, err := src.refs()
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		hash, ok := all[ref]
+		if !ok {
+			return &GitError{Op: "fetch", ExitCode: 128, Stderr: fmt.Sprintf("couldn't find remote ref %s", ref)}
+		}
+		if err := copyObjects(ctx, src, b.storage, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyObjects copies an object and everything it references that dst is
+// missing. Referenced objects are written first so an interrupted copy never
+// leaves an object whose history is incomplete.
+func copyObjects(ctx context.Context, src, dst objectStorage, hash string) error {
+	type object struct {
+		hash, kind string
+		data       []byte
+	}
+
+	var missing []object
+	seen := make(map[string]bool)
+	pending := []string{hash}
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[hash] || dst.hasObject(hash) {
+			continue
+		}
+		seen[hash] = true
+
+		kind, data, err := src.readObject(hash)
+		if err != nil {
+			return err
+		}
+		missing = append(missing, object{hash, kind, data})
+
+		switch kind {
+		case "commit", "tag":
+			for _, h := range parseObject(data).headers {
+				if h[0] == "tree" || h[0] == "parent" || h[0] == "object" {
+					pending = append(pending, h[1])
+				}
+			}
+		case "tree":
+			pending = append(pending, treeEntries(data)...)
+		}
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := dst.writeObject(missing[i].hash, missing[i].kind, missing[i].data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// treeEntries returns the objects listed in a tree, skipping submodule commits
+func treeEntries(data []byte) []string {
+	var hashes []string
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 || len(data) < end+21 {
+			break
+		}
+		mode := string(data[:bytes.IndexByte(data[:end], ' ')+1])
+		if mode != "160000 " {
+			hashes = append(hashes, hex.EncodeToString(data[end+1:end+21]))
+		}
+		data = data[end+21:]
+	}
+	return hashes
+}
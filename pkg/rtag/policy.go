@@ -0,0 +1,338 @@
+package rtag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy holds the rules a release must follow. Every rule is optional.
+//
+// The approvals and releasers rules are advisory. Approvals are commit message
+// trailers that anyone with push access can write, and the releaser is the git
+// committer identity, which is set by the user or the environment. They catch
+// mistakes, not someone set on bypassing them: enforce reviews and who may
+// push tags on the hosting service.
+type Policy struct {
+	Branches    []string `json:"branches"`     // branches releases are made from, patterns such as "release/*" allowed
+	CleanTree   bool     `json:"clean_tree"`   // no uncommitted changes
+	MinInterval string   `json:"min_interval"` // minimum time between two releases of a component, e.g. "1h"
+	Approvals   int      `json:"approvals"`    // Approved-by trailers required on the released commit, other than its author's and the releaser's
+	Releasers   []string `json:"releasers"`    // emails or names of the people allowed to release
+	Days        []string `json:"days"`         // weekdays releases are allowed on, e.g. "mon"
+	Hours       string   `json:"hours"`        // hours releases are allowed in, e.g. "9-17" or "22-6"
+	Timezone    string   `json:"timezone"`     // zone of Days and Hours, e.g. "Europe/Paris", local time by default
+}
+
+// ApprovalTrailer is the commit message trailer counted by the approvals rule
+const ApprovalTrailer = "Approved-by"
+
+// PolicyRule names a rule of a Policy
+type PolicyRule string
+
+const (
+	RuleBranch      PolicyRule = "branches"
+	RuleCleanTree   PolicyRule = "clean_tree"
+	RuleMinInterval PolicyRule = "min_interval"
+	RuleApprovals   PolicyRule = "approvals"
+	RuleReleasers   PolicyRule = "releasers"
+	RuleDays        PolicyRule = "days"
+	RuleHours       PolicyRule = "hours"
+	RuleTimezone    PolicyRule = "timezone"
+)
+
+// Violation is a rule a release breaks
+type Violation struct {
+	Rule      PolicyRule
+	Component string // set for rules checked per component
+	Tag       string // the previous release, for min_interval
+	Actual    string // what was found, e.g. the current branch
+	Allowed   string // what the rule allows
+}
+
+// ErrInvalidPolicy is wrapped by *PolicyError
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// PolicyError reports a rule whose setting cannot be understood
+type PolicyError struct {
+	Rule  PolicyRule
+	Value string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("%v: %s %q", ErrInvalidPolicy, e.Rule, e.Value)
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrInvalidPolicy
+}
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// CheckPolicy evaluates policy for releasing commit, or HEAD when it is
+// empty, as components at now and returns every rule that is broken.
+// Nothing is created.
+func (p *Project) CheckPolicy(ctx context.Context, policy Policy, commit string, components []string, now time.Time) ([]Violation, error) {
+	var violations []Violation
+
+	if len(policy.Branches) > 0 {
+		branch, err := p.backend.Branch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !MatchAny(policy.Branches, branch) {
+			violations = append(violations, Violation{Rule: RuleBranch, Actual: branch, Allowed: strings.Join(policy.Branches, ", ")})
+		}
+	}
+
+	if policy.CleanTree {
+		changes, err := p.backend.ChangedFiles(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if changes > 0 {
+			violations = append(violations, Violation{Rule: RuleCleanTree, Actual: strconv.Itoa(changes), Allowed: "0"})
+		}
+	}
+
+	if len(policy.Releasers) > 0 {
+		releaser, err := p.backend.Identity(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !allowedReleaser(policy.Releasers, releaser) {
+			violations = append(violations, Violation{
+				Rule:    RuleReleasers,
+				Actual:  fmt.Sprintf("%s <%s>", releaser.Name, releaser.Email),
+				Allowed: strings.Join(policy.Releasers, ", "),
+			})
+		}
+	}
+
+	if policy.Approvals > 0 {
+		approvals, err := p.approvals(ctx, commit)
+		if err != nil {
+			return nil, err
+		}
+		if approvals < policy.Approvals {
+			violations = append(violations, Violation{Rule: RuleApprovals, Actual: strconv.Itoa(approvals), Allowed: strconv.Itoa(policy.Approvals)})
+		}
+	}
+
+	window, err := policy.checkWindow(now)
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, window...)
+
+	if policy.MinInterval != "" {
+		interval, err := time.ParseDuration(policy.MinInterval)
+		if err != nil || interval < 0 {
+			return nil, &PolicyError{Rule: RuleMinInterval, Value: policy.MinInterval}
+		}
+
+		latest, err := p.latestReleases(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, component := range components {
+			last, ok := latest[component]
+			if !ok {
+				continue
+			}
+			released, err := time.ParseInLocation(TimestampFormat, last.Timestamp, time.Local)
+			if err != nil {
+				continue
+			}
+			if since := now.Sub(released); since < interval {
+				violations = append(violations, Violation{
+					Rule:      RuleMinInterval,
+					Component: component,
+					Tag:       last.Tag,
+					Actual:    formatDuration(since.Round(time.Minute)),
+					Allowed:   formatDuration(interval),
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// latestReleases returns the newest release of each component, here or on
+// the remote. Callers holding the release lock thus see a release that was
+// just pushed from another clone. An unreachable remote is skipped, pushing
+// the release would fail anyway.
+func (p *Project) latestReleases(ctx context.Context) (map[string]Release, error) {
+	releases, err := p.Releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]Release, len(releases))
+	for component, history := range releases {
+		latest[component] = history[len(history)-1]
+	}
+
+	refs, err := p.backend.RemoteRefs(ctx, p.remote, "refs/tags/")
+	if err != nil {
+		return latest, nil
+	}
+	for name := range refs {
+		// Peeled annotated tags are listed a second time with a ^{} suffix
+		tag := strings.TrimPrefix(name, "refs/tags/")
+		values, ok := p.naming.Match(tag)
+		if !ok || strings.HasSuffix(tag, "^{}") {
+			continue
+		}
+		component := values["component"]
+		if last, ok := latest[component]; !ok || values["timestamp"] > last.Timestamp {
+			latest[component] = Release{Tag: tag, Component: component, Timestamp: values["timestamp"]}
+		}
+	}
+	return latest, nil
+}
+
+// checkWindow evaluates the days and hours rules
+func (policy Policy) checkWindow(now time.Time) ([]Violation, error) {
+	if len(policy.Days) == 0 && policy.Hours == "" {
+		return nil, nil
+	}
+
+	if policy.Timezone != "" {
+		location, err := time.LoadLocation(policy.Timezone)
+		if err != nil {
+			return nil, &PolicyError{Rule: RuleTimezone, Value: policy.Timezone}
+		}
+		now = now.In(location)
+	}
+
+	var violations []Violation
+	if len(policy.Days) > 0 {
+		allowed := false
+		for _, day := range policy.Days {
+			day = strings.ToLower(day)
+			if !containsString(weekdays, day) {
+				return nil, &PolicyError{Rule: RuleDays, Value: day}
+			}
+			allowed = allowed || day == weekdays[now.Weekday()]
+		}
+		if !allowed {
+			violations = append(violations, Violation{Rule: RuleDays, Actual: weekdays[now.Weekday()], Allowed: strings.Join(policy.Days, ", ")})
+		}
+	}
+
+	if policy.Hours != "" {
+		start, end, err := parseHours(policy.Hours)
+		if err != nil {
+			return nil, err
+		}
+
+		// A range such as 22-6 spans midnight
+		hour := now.Hour()
+		allowed := start <= hour && hour < end
+		if start > end {
+			allowed = hour >= start || hour < end
+		}
+		if !allowed {
+			violations = append(violations, Violation{Rule: RuleHours, Actual: now.Format("15:04 MST"), Allowed: policy.Hours})
+		}
+	}
+	return violations, nil
+}
+
+// formatDuration formats d without trailing zero units, e.g. 1h instead of 1h0m0s
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// parseHours parses an hour range such as "9-17", the end hour is excluded
+func parseHours(hours string) (int, int, error) {
+	from, to, found := strings.Cut(hours, "-")
+	start, err1 := strconv.Atoi(strings.TrimSpace(from))
+	end, err2 := strconv.Atoi(strings.TrimSpace(to))
+	if !found || err1 != nil || err2 != nil || start < 0 || start > 23 || end < 0 || end > 24 || start == end {
+		return 0, 0, &PolicyError{Rule: RuleHours, Value: hours}
+	}
+	return start, end, nil
+}
+
+// approvals counts the distinct Approved-by trailers of commit, HEAD when it
+// is empty. The author of the commit and the releaser cannot approve it
+// themselves.
+func (p *Project) approvals(ctx context.Context, commit string) (int, error) {
+	if commit == "" {
+		commit = "HEAD"
+	}
+	since := commit + "^"
+	if _, err := p.backend.ResolveRef(ctx, since); errors.Is(err, ErrNotFound) {
+		since = ""
+	}
+
+	commits, err := p.backend.Log(ctx, since, commit)
+	if err != nil || len(commits) == 0 {
+		return 0, err
+	}
+
+	releaser, err := p.backend.Identity(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	approvers := make(map[string]bool)
+	for _, line := range strings.Split(commits[0].Body, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		if !found || !strings.EqualFold(strings.TrimSpace(key), ApprovalTrailer) || value == "" {
+			continue
+		}
+		if sameIdentity(value, commits[0].Author) || sameIdentity(value, releaser) {
+			continue
+		}
+		approvers[strings.ToLower(value)] = true
+	}
+	return len(approvers), nil
+}
+
+// sameIdentity reports whether a "Name <email>" trailer value, or a bare
+// name or email, designates sig
+func sameIdentity(value string, sig Signature) bool {
+	name, email := value, value
+	if before, rest, found := strings.Cut(value, "<"); found {
+		name = strings.TrimSpace(before)
+		email, _, _ = strings.Cut(rest, ">")
+	}
+	return (sig.Email != "" && strings.EqualFold(strings.TrimSpace(email), sig.Email)) || (sig.Name != "" && name == sig.Name)
+}
+
+// allowedReleaser reports whether the email or name of releaser is listed
+func allowedReleaser(releasers []string, releaser Signature) bool {
+	for _, allowed := range releasers {
+		if strings.EqualFold(allowed, releaser.Email) || allowed == releaser.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAny reports whether name matches one of the patterns, which are
+// path.Match patterns such as "release/*" or plain names
+func MatchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok || pattern == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package rtag
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCheckPolicyMinIntervalSeesRemoteReleases(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 6, 12, 0, 0, 0, time.Local)
+
+	m := NewMemoryBackend(t.TempDir())
+	commit, err := m.Commit("initial", now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := m.AddRemote("origin")
+	if err := m.PushRefs(ctx, "origin", []string{"refs/heads/main:refs/heads/main"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// api was released 10 minutes ago from another clone, cron locally an hour ago
+	if err := remote.CreateTag(ctx, "release-"+now.Add(-10*time.Minute).Format(TimestampFormat)+"-api", commit, TagOptions{Message: "api"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateTag(ctx, "release-"+now.Add(-time.Hour).Format(TimestampFormat)+"-cron", commit, TagOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := New(t.TempDir(), WithBackend(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations, err := project.CheckPolicy(ctx, Policy{MinInterval: "30m"}, "", []string{"api", "cron", "web"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 1 || violations[0].Component != "api" || violations[0].Actual != "10m" {
+		t.Errorf("CheckPolicy() = %+v, want api released 10m ago", violations)
+	}
+}
+
+func TestCheckPolicyIgnoresSelfApprovals(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("GIT_COMMITTER_NAME", "Rita Releaser")
+	t.Setenv("GIT_COMMITTER_EMAIL", "rita@example.com")
+
+	m := NewMemoryBackend(t.TempDir())
+	m.repo.settings["user.name"] = "Alan Author"
+	m.repo.settings["user.email"] = "alan@example.com"
+	message := "Fix the thing\n\n" +
+		"Approved-by: Alan Author <alan@example.com>\n" + // the author
+		"Approved-by: rita@example.com\n" + // the releaser
+		"Approved-by: Rita R. <RITA@example.com>\n" + // the releaser under another name
+		"Approved-by: Bea Reviewer <bea@example.com>\n" +
+		"approved-by: bea reviewer <bea@example.com>\n" + // the same approval twice
+		"Approved-by: Carl <carl@example.com>"
+	if _, err := m.Commit(message, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := New(t.TempDir(), WithBackend(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations, err := project.CheckPolicy(ctx, Policy{Approvals: 3}, "", nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 1 || violations[0].Rule != RuleApprovals || violations[0].Actual != "2" {
+		t.Errorf("CheckPolicy() = %+v, want 2 of 3 approvals", violations)
+	}
+}
+
+func TestCheckPolicyApprovalsOfReleasedCommit(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryBackend(t.TempDir())
+	approved, err := m.Commit("Fix the thing\n\nApproved-by: Bea Reviewer <bea@example.com>", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Commit("Start the next thing", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := New(t.TempDir(), WithBackend(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A rollback releases an older commit than HEAD, its approvals count
+	for commit, want := range map[string]int{approved: 0, "": 1, "HEAD": 1} {
+		violations, err := project.CheckPolicy(ctx, Policy{Approvals: 1}, commit, nil, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(violations) != want {
+			t.Errorf("CheckPolicy(%q) = %+v, want %d violations", commit, violations, want)
+		}
+	}
+}
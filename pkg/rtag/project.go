@@ -0,0 +1,466 @@
+// Package rtag manages the release tags of the components listed in a .rtag
+// file. It is the library behind the rtag command and never prints: results
+// and errors are returned to the caller.
+package rtag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Errors wrapped in *NameError
+var (
+	ErrInvalidTagName  = errors.New("invalid tag name")
+	ErrTagExists       = errors.New("tag already exists")
+	ErrTagNotFound     = errors.New("tag does not exist")
+	ErrInvalidTemplate = errors.New("invalid tag template")
+	ErrUnknownField    = errors.New("unknown placeholder in tag template")
+	ErrMissingField    = errors.New("tag template is missing a placeholder")
+
+	ErrInvalidTagFileLine = errors.New("group or dependency line without separator")
+)
+
+// NameError reports a problem with a tag name or tag template
+type NameError struct {
+	Name  string // the tag or template
+	Field string // the template placeholder concerned, if any
+	Err   error
+}
+
+func (e *NameError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("%s: %v {%s}", e.Name, e.Err, e.Field)
+	}
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *NameError) Unwrap() error {
+	return e.Err
+}
+
+// YankedRefPrefix is the ref namespace recording yanked releases. Each yanked
+// release tag has a companion ref pointing at a blob holding the yank reason.
+const YankedRefPrefix = "refs/yanked/"
+
+// Release is a release tag of a component
+type Release struct {
+	Tag        string
+	Component  string
+	Timestamp  string
+	Commit     string
+	Yanked     bool
+	YankReason string
+}
+
+// Project is a repository whose components are released with rtag
+type Project struct {
+	root    string
+	tagFile string
+	backend Backend
+	naming  Template
+	remote  string
+	retry   RetryPolicy
+}
+
+// Option configures a Project
+type Option func(*Project) error
+
+// WithBackend sets the git backend, DefaultBackend(root) by default
+func WithBackend(backend Backend) Option {
+	return func(p *Project) error {
+		p.backend = backend
+		return nil
+	}
+}
+
+// WithTagFile sets the path of the .rtag file, root/.rtag by default
+func WithTagFile(path string) Option {
+	return func(p *Project) error {
+		p.tagFile = path
+		return nil
+	}
+}
+
+// WithNaming sets the template of release tag names, DefaultTemplate by
+// default. It must contain {component} and {timestamp}.
+func WithNaming(pattern string) Option {
+	return func(p *Project) error {
+		template, err := ParseTemplate(pattern)
+		if err != nil {
+			return err
+		}
+		if !template.Has("timestamp") {
+			return &NameError{Name: pattern, Field: "timestamp", Err: ErrMissingField}
+		}
+		if template.Has("version") {
+			return &NameError{Name: pattern, Field: "version", Err: ErrUnknownField}
+		}
+		p.naming = template
+		return nil
+	}
+}
+
+// WithRemote sets the remote release tags are pushed to, origin by default
+func WithRemote(name string) Option {
+	return func(p *Project) error {
+		p.remote = name
+		return nil
+	}
+}
+
+// WithRetry sets how failed pushes are retried, DefaultRetryPolicy by default
+func WithRetry(policy RetryPolicy) Option {
+	return func(p *Project) error {
+		p.retry = policy
+		return nil
+	}
+}
+
+// New returns the project of the repository checked out at root
+func New(root string, opts ...Option) (*Project, error) {
+	naming, err := ParseTemplate(DefaultTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Project{
+		root:    root,
+		tagFile: filepath.Join(root, TagFileName),
+		naming:  naming,
+		remote:  "origin",
+		retry:   DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	if p.backend == nil {
+		p.backend = DefaultBackend(root)
+	}
+	return p, nil
+}
+
+// Backend returns the git backend of the project
+func (p *Project) Backend() Backend {
+	return p.backend
+}
+
+// Naming returns the template of release tag names
+func (p *Project) Naming() Template {
+	return p.naming
+}
+
+// TagFile returns the content of the .rtag file
+func (p *Project) TagFile() (TagFile, error) {
+	return ReadTagFile(p.tagFile)
+}
+
+// Components returns the tags listed in the .rtag file
+func (p *Project) Components() ([]string, error) {
+	content, err := p.TagFile()
+	if err != nil {
+		return nil, err
+	}
+	return content.Tags, nil
+}
+
+// Add lists new components in the .rtag file. Nothing is written if any of
+// them is invalid or already listed. Concurrent calls, including from other
+// processes, are serialized.
+func (p *Project) Add(components ...string) error {
+	return UpdateTagFile(p.tagFile, func(content *TagFile) error {
+		return content.Add(components...)
+	})
+}
+
+// Remove deletes components from the .rtag file, including from its groups
+// and dependencies. Nothing is written if any of them is not listed.
+func (p *Project) Remove(components ...string) error {
+	return UpdateTagFile(p.tagFile, func(content *TagFile) error {
+		return content.Remove(components...)
+	})
+}
+
+// ReleaseOptions controls Release
+type ReleaseOptions struct {
+	Time     time.Time // timestamp of the release tags, now by default
+	Target   string    // commit to release, HEAD by default
+	Floating bool      // also move the floating tags, release-latest-{component} by default
+	NoPush   bool      // create the tags without pushing them
+	Message  string    // annotates the release tags, floating tags stay lightweight
+}
+
+// ReleasedTag is the outcome of releasing one component
+type ReleasedTag struct {
+	Component   string
+	Tag         string
+	Err         error  // creating the tag failed
+	Floating    string // floating tag moved to Tag, if requested
+	FloatingOld string // commit the floating tag pointed to before, "" if it was new
+}
+
+// ReleaseResult is the outcome of Release
+type ReleaseResult struct {
+	Timestamp string
+	Tags      []ReleasedTag
+	Refspecs  []string // refspecs to push for the created and moved tags
+	Pushed    bool
+	Push      PushResult
+}
+
+// Created returns the release tags that were created
+func (r ReleaseResult) Created() []string {
+	var created []string
+	for _, tag := range r.Tags {
+		if tag.Err == nil {
+			created = append(created, tag.Tag)
+		}
+	}
+	return created
+}
+
+// Release tags the target commit for each component and pushes the tags. The
+// tags and floating tags are written in one transaction, so either all of
+// them are created or none is. A component whose tag already exists is
+// reported in the result and does not stop the others. The returned error is
+// set when the target cannot be resolved, the backend cannot push to the
+// remote, the tags cannot be written or the push fails.
+func (p *Project) Release(ctx context.Context, components []string, opts ReleaseOptions) (ReleaseResult, error) {
+	when := opts.Time
+	if when.IsZero() {
+		when = time.Now()
+	}
+	target := opts.Target
+	if target == "" {
+		target = "HEAD"
+	}
+
+	result := ReleaseResult{Timestamp: when.Format(TimestampFormat)}
+	commit, err := p.backend.ResolveRef(ctx, target)
+	if err != nil {
+		return result, err
+	}
+
+	// Tags that cannot be pushed are not created in the first place
+	if !opts.NoPush {
+		if err := p.backend.CheckPush(ctx, p.remote); err != nil {
+			return result, err
+		}
+	}
+
+	// One listing of the existing tags instead of a lookup per component
+	refs, err := p.backend.ListTags(ctx)
+	if err != nil {
+		return result, err
+	}
+	existing := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		existing[ref.Name] = ref.Commit
+	}
+
+	var updates []TagUpdate
+	for _, component := range components {
+		tag := ReleasedTag{
+			Component: component,
+			Tag:       p.naming.Format(map[string]string{"component": component, "timestamp": result.Timestamp}),
+		}
+
+		if _, ok := existing[tag.Tag]; ok {
+			tag.Err = &NameError{Name: tag.Tag, Err: ErrTagExists}
+		} else {
+			existing[tag.Tag] = commit
+			updates = append(updates, TagUpdate{Name: tag.Tag, Commit: commit, Message: opts.Message})
+			if opts.Floating {
+				tag.Floating = p.FloatingTagName(component)
+				tag.FloatingOld = existing[tag.Floating]
+				updates = append(updates, TagUpdate{Name: tag.Floating, Commit: commit, Force: true})
+			}
+		}
+		result.Tags = append(result.Tags, tag)
+	}
+
+	if err := p.backend.UpdateTags(ctx, updates); err != nil {
+		for i := range result.Tags {
+			if result.Tags[i].Err == nil {
+				result.Tags[i].Err = err
+			}
+		}
+		return result, err
+	}
+
+	for _, tag := range result.Tags {
+		if tag.Err != nil {
+			continue
+		}
+		result.Refspecs = append(result.Refspecs, TagRefspec(tag.Tag))
+		if tag.Floating != "" {
+			result.Refspecs = append(result.Refspecs, "+"+TagRefspec(tag.Floating))
+		}
+	}
+
+	if opts.NoPush || len(result.Refspecs) == 0 {
+		return result, nil
+	}
+
+	result.Push, err = p.Push(ctx, result.Refspecs)
+	if err != nil {
+		return result, err
+	}
+	result.Pushed = true
+	return result, nil
+}
+
+// Push pushes refspecs to the remote of the project, retrying transient
+// failures according to the retry policy
+func (p *Project) Push(ctx context.Context, refspecs []string) (PushResult, error) {
+	return PushWithRetry(ctx, p.backend, p.remote, refspecs, p.retry)
+}
+
+// FloatingTagName returns the moving tag that always points at the latest
+// release of a component, named after the release tag template
+func (p *Project) FloatingTagName(component string) string {
+	return p.naming.FloatingName(component)
+}
+
+// MoveFloatingTag force-moves the floating tag of a component to a release
+// tag and returns the force refspec needed to push it
+func (p *Project) MoveFloatingTag(ctx context.Context, component, tag string) (string, error) {
+	floating := p.FloatingTagName(component)
+	if err := p.backend.CreateTag(ctx, floating, tag, TagOptions{Force: true}); err != nil {
+		return "", err
+	}
+	return "+" + TagRefspec(floating), nil
+}
+
+// History returns the releases of a component from oldest to newest
+func (p *Project) History(ctx context.Context, component string) ([]Release, error) {
+	return ListReleases(ctx, p.backend, p.naming, component)
+}
+
+// TagRefspec returns the refspec pushing a tag to the same name on the remote
+func TagRefspec(tag string) string {
+	return "refs/tags/" + tag + ":refs/tags/" + tag
+}
+
+// Releases returns the releases of every component, each from oldest to newest
+func (p *Project) Releases(ctx context.Context) (map[string][]Release, error) {
+	return ListAllReleases(ctx, p.backend, p.naming)
+}
+
+// ListReleases returns the tags of a component named by template, from oldest
+// to newest. They are ordered by {timestamp}, or by creation date for
+// templates without one.
+func ListReleases(ctx context.Context, backend Backend, template Template, component string) ([]Release, error) {
+	releases, err := ListAllReleases(ctx, backend, template)
+	if err != nil {
+		return nil, err
+	}
+	return releases[component], nil
+}
+
+// ListAllReleases returns the tags named by template grouped by component,
+// each group ordered like ListReleases. The tags are listed once, which is
+// much faster than calling ListReleases for each of many components.
+func ListAllReleases(ctx context.Context, backend Backend, template Template) (map[string][]Release, error) {
+	refs, err := backend.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	yanked, err := Yanked(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make(map[string][]Release)
+	for _, ref := range refs {
+		// Floating tags such as release-latest-{tag} have no timestamp and do not match
+		values, ok := template.Match(ref.Name)
+		if !ok {
+			continue
+		}
+
+		component := values["component"]
+		reason, isYanked := yanked[ref.Name]
+		releases[component] = append(releases[component], Release{
+			Tag:        ref.Name,
+			Component:  component,
+			Timestamp:  values["timestamp"],
+			Commit:     ref.Commit,
+			Yanked:     isYanked,
+			YankReason: reason,
+		})
+	}
+
+	for _, list := range releases {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Timestamp < list[j].Timestamp
+		})
+	}
+
+	return releases, nil
+}
+
+// FetchYanked copies the yanks recorded on remote that are missing locally,
+// so releases yanked from another clone are skipped here too
+func FetchYanked(ctx context.Context, backend Backend, remote string) error {
+	refs, err := backend.RemoteRefs(ctx, remote, YankedRefPrefix)
+	if err != nil {
+		return err
+	}
+	local, err := backend.ReadBlobRefs(ctx, YankedRefPrefix)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for name := range refs {
+		if _, ok := local[strings.TrimPrefix(name, YankedRefPrefix)]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+
+	if err := backend.FetchRefs(ctx, remote, missing); err != nil {
+		return err
+	}
+	for _, name := range missing {
+		reason, err := backend.ReadBlob(ctx, refs[name])
+		if err != nil {
+			return err
+		}
+		// The blob has the same content, so the local ref gets the same hash
+		if err := backend.WriteBlobRef(ctx, name, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FetchYanked copies the yanks recorded on the remote of the project that are missing locally
+func (p *Project) FetchYanked(ctx context.Context) error {
+	return FetchYanked(ctx, p.backend, p.remote)
+}
+
+// Yanked returns the yanked release tags mapped to their yank reasons
+func Yanked(ctx context.Context, backend Backend) (map[string]string, error) {
+	reasons, err := backend.ReadBlobRefs(ctx, YankedRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	yanked := make(map[string]string)
+	for tag, reason := range reasons {
+		yanked[tag] = strings.TrimSpace(reason)
+	}
+	return yanked, nil
+}
@@ -0,0 +1,107 @@
+package rtag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// benchComponents is the size of the monorepo the benchmarks release
+const benchComponents = 300
+
+// benchProject returns a project of benchComponents components with one
+// commit and an origin to push to, over the memory or exec backend
+func benchProject(b *testing.B, kind string) (*Project, []string) {
+	b.Helper()
+	dir := b.TempDir()
+
+	var backend Backend
+	switch kind {
+	case "memory":
+		m := NewMemoryBackend(dir)
+		if _, err := m.Commit("initial", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+			b.Fatal(err)
+		}
+		m.AddRemote("origin")
+		backend = m
+	case "exec":
+		setGitEnv(b)
+		remote := b.TempDir()
+		runGit(b, remote, "init", "-q", "--bare")
+		runGit(b, dir, "init", "-q", "-b", "main")
+		runGit(b, dir, "remote", "add", "origin", remote)
+		runGit(b, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+		backend = NewExecBackend(dir)
+	}
+
+	components := make([]string, benchComponents)
+	for i := range components {
+		components[i] = fmt.Sprintf("service-%03d", i)
+	}
+	tagFile := filepath.Join(b.TempDir(), TagFileName)
+	if err := os.WriteFile(tagFile, nil, 0644); err != nil {
+		b.Fatal(err)
+	}
+	project, err := New(dir, WithBackend(backend), WithTagFile(tagFile), WithRetry(RetryPolicy{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := project.Add(components...); err != nil {
+		b.Fatal(err)
+	}
+	return project, components
+}
+
+// BenchmarkRelease measures releasing and pushing every component of a large monorepo
+func BenchmarkRelease(b *testing.B) {
+	for _, kind := range []string{"memory", "exec"} {
+		b.Run(kind, func(b *testing.B) {
+			ctx := context.Background()
+			project, components := benchProject(b, kind)
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				result, err := project.Release(ctx, components, ReleaseOptions{Time: start.Add(time.Duration(i) * time.Minute), Floating: true})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if created := len(result.Created()); created != benchComponents {
+					b.Fatalf("created %d tags, want %d", created, benchComponents)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkHistory measures reading the releases of every component when
+// each has a few releases
+func BenchmarkHistory(b *testing.B) {
+	for _, kind := range []string{"memory", "exec"} {
+		b.Run(kind, func(b *testing.B) {
+			ctx := context.Background()
+			project, components := benchProject(b, kind)
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			for i := 0; i < 3; i++ {
+				opts := ReleaseOptions{Time: start.Add(time.Duration(i) * time.Hour), NoPush: true, Message: fmt.Sprintf("release %d", i)}
+				if _, err := project.Release(ctx, components, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				releases, err := project.Releases(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(releases) != benchComponents || len(releases[components[0]]) != 3 {
+					b.Fatalf("found the releases of %d components", len(releases))
+				}
+			}
+		})
+	}
+}
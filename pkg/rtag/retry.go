@@ -0,0 +1,176 @@
+package rtag
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed pushes are retried
+type RetryPolicy struct {
+	Retries  int           // retries after the first attempt, 0 disables retrying
+	Delay    time.Duration // wait before the first retry, doubled for each following one
+	MaxDelay time.Duration // upper bound of the wait, unbounded when zero
+}
+
+// DefaultRetryPolicy retries three times, waiting 1s, 2s and 4s
+var DefaultRetryPolicy = RetryPolicy{Retries: 3, Delay: time.Second, MaxDelay: 30 * time.Second}
+
+// PushAttempt is one push of PushWithRetry
+type PushAttempt struct {
+	Refspecs []string
+	Err      error
+	Retry    []string      // refspecs pushed again by the next attempt
+	Delay    time.Duration // wait before the next attempt
+}
+
+// PushResult is the outcome of PushWithRetry
+type PushResult struct {
+	Attempts []PushAttempt
+	Pushed   []string       // refspecs the remote accepted
+	Failed   []RefRejection // refspecs that were not pushed
+}
+
+// Messages of failures that usually go away when the push is tried again:
+// network errors, overloaded servers and refs locked by a concurrent push
+var retryableMessages = []string{
+	"cannot lock ref",
+	"unable to lock",
+	"failed to lock",
+	"could not resolve host",
+	"connection refused",
+	"connection reset",
+	"connection timed out",
+	"operation timed out",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"rpc failed",
+	"broken pipe",
+	"temporarily unavailable",
+	"the requested url returned error: 429",
+	"the requested url returned error: 5",
+}
+
+// IsRetryable reports whether a push failure is transient. Rejections such
+// as an existing tag, a declined hook or missing permissions are permanent.
+// A deadline set on an attempt is transient, a cancelled context is not.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var pushErr *PushError
+	if errors.As(err, &pushErr) {
+		for _, rejection := range pushErr.Rejected {
+			if retryableMessage(rejection.Reason) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return retryableMessage(err.Error())
+}
+
+func retryableMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, retryable := range retryableMessages {
+		if strings.Contains(message, retryable) {
+			return true
+		}
+	}
+	return false
+}
+
+// PushWithRetry pushes refspecs to remote and retries transient failures with
+// exponential backoff. Each retry only pushes the refs that were not accepted
+// yet and whose failure is retryable. The error is set when some refs could
+// not be pushed, the result then tells which ones.
+func PushWithRetry(ctx context.Context, backend Backend, remote string, refspecs []string, policy RetryPolicy) (PushResult, error) {
+	var result PushResult
+	pending := refspecs
+	delay := policy.Delay
+
+	for attempt := 0; ; attempt++ {
+		err := backend.PushRefs(ctx, remote, pending)
+		current := PushAttempt{Refspecs: pending, Err: err}
+
+		var failed []RefRejection
+		var pushErr *PushError
+		switch {
+		case err == nil:
+		case errors.As(err, &pushErr):
+			failed = pushErr.Rejected
+		default:
+			for _, refspec := range pending {
+				failed = append(failed, RefRejection{Refspec: refspec, Reason: err.Error()})
+			}
+		}
+
+		result.Pushed = append(result.Pushed, accepted(pending, failed)...)
+
+		var permanent []RefRejection
+		for _, rejection := range failed {
+			if ctx.Err() == nil && attempt < policy.Retries && IsRetryable(&PushError{Rejected: []RefRejection{rejection}, Err: err}) {
+				current.Retry = append(current.Retry, rejection.Refspec)
+			} else {
+				permanent = append(permanent, rejection)
+			}
+		}
+		result.Failed = append(result.Failed, permanent...)
+
+		if len(current.Retry) == 0 {
+			result.Attempts = append(result.Attempts, current)
+			break
+		}
+
+		current.Delay = delay
+		result.Attempts = append(result.Attempts, current)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			for _, refspec := range current.Retry {
+				result.Failed = append(result.Failed, RefRejection{Refspec: refspec, Reason: ctx.Err().Error()})
+			}
+			return result, ctx.Err()
+		case <-timer.C:
+		}
+
+		pending = current.Retry
+		delay *= 2
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+
+	if len(result.Failed) == 0 {
+		return result, nil
+	}
+	last := result.Attempts[len(result.Attempts)-1].Err
+	if len(result.Failed) == len(refspecs) {
+		return result, last
+	}
+	return result, &PushError{Rejected: result.Failed, Err: last}
+}
+
+// accepted returns the refspecs that are not rejected
+func accepted(refspecs []string, rejected []RefRejection) []string {
+	refused := make(map[string]bool, len(rejected))
+	for _, rejection := range rejected {
+		refused[rejection.Refspec] = true
+	}
+
+	var refs []string
+	for _, refspec := range refspecs {
+		if !refused[refspec] {
+			refs = append(refs, refspec)
+		}
+	}
+	return refs
+}
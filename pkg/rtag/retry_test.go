@@ -0,0 +1,385 @@
+package rtag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyBackend is a memory backend whose remote refuses some refspecs with
+// the given reasons, one per attempt, before accepting them
+type flakyBackend struct {
+	*MemoryBackend
+	failures map[string][]string // reasons by refspec, consumed in order
+	err      error               // fails every push as a whole when set
+	pushes   [][]string          // refspecs of each PushRefs call
+}
+
+func (b *flakyBackend) PushRefs(ctx context.Context, remote string, refspecs []string) error {
+	b.pushes = append(b.pushes, refspecs)
+	if b.err != nil {
+		return b.err
+	}
+
+	var pass []string
+	var rejected []RefRejection
+	for _, refspec := range refspecs {
+		if reasons := b.failures[refspec]; len(reasons) > 0 {
+			b.failures[refspec] = reasons[1:]
+			rejected = append(rejected, RefRejection{Refspec: refspec, Reason: reasons[0]})
+			continue
+		}
+		pass = append(pass, refspec)
+	}
+
+	err := b.MemoryBackend.PushRefs(ctx, remote, pass)
+	var pushErr *PushError
+	switch {
+	case err == nil:
+	case errors.As(err, &pushErr):
+		rejected = append(rejected, pushErr.Rejected...)
+	default:
+		return err
+	}
+	if len(rejected) > 0 {
+		return &PushError{Rejected: rejected}
+	}
+	return nil
+}
+
+// newFlakyBackend returns a backend with the tags a, b and c on its commit and
+// an origin remote on which a already points at another commit
+func newFlakyBackend(t *testing.T) (*flakyBackend, *MemoryBackend) {
+	t.Helper()
+	ctx := context.Background()
+
+	m := NewMemoryBackend(t.TempDir())
+	commit, err := m.Commit("initial", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if err := m.CreateTag(ctx, name, commit, TagOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remote := m.AddRemote("origin")
+	other, err := remote.Commit("other", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.CreateTag(ctx, "a", other, TagOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	return &flakyBackend{MemoryBackend: m, failures: make(map[string][]string)}, remote
+}
+
+func tagRefspecs(names ...string) []string {
+	refspecs := make([]string, len(names))
+	for i, name := range names {
+		refspecs[i] = "refs/tags/" + name + ":refs/tags/" + name
+	}
+	return refspecs
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"cancelled", context.Canceled, false},
+		{"cancelled while pushing", fmt.Errorf("push: %w", context.Canceled), false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"unreachable host", &GitError{Op: "push", ExitCode: 128, Stderr: "fatal: unable to access 'https://example.com/repo.git/': Could not resolve host: example.com"}, true},
+		{"hung up", &GitError{Op: "push", ExitCode: 128, Stderr: "fatal: the remote end hung up unexpectedly"}, true},
+		{"server error", errors.New("The requested URL returned error: 503"), true},
+		{"rate limited", errors.New("The requested URL returned error: 429"), true},
+		{"not found", errors.New("The requested URL returned error: 404"), false},
+		{"permission denied", &GitError{Op: "push", ExitCode: 128, Stderr: "ERROR: Permission to org/repo.git denied to user."}, false},
+		{"locked ref", &PushError{Rejected: []RefRejection{{Refspec: "refs/tags/a", Reason: "cannot lock ref"}}}, true},
+		{"existing tag", &PushError{Rejected: []RefRejection{{Refspec: "refs/tags/a", Reason: "already exists"}}}, false},
+		{"declined by hook", &PushError{Rejected: []RefRejection{{Refspec: "refs/tags/a", Reason: "pre-receive hook declined"}}}, false},
+		{"one ref locked", &PushError{Rejected: []RefRejection{
+			{Refspec: "refs/tags/a", Reason: "already exists"},
+			{Refspec: "refs/tags/b", Reason: "cannot lock ref"},
+		}}, true},
+		// The reasons of the refs decide, not the failure git reported for the whole push
+		{"rejection with transient git error", &PushError{
+			Rejected: []RefRejection{{Refspec: "refs/tags/a", Reason: "already exists"}},
+			Err:      errors.New("connection reset by peer"),
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushWithRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		refspecs []string
+		failures map[string][]string
+		err      error
+		policy   RetryPolicy
+		pushes   [][]string
+		delays   []time.Duration
+		pushed   []string
+		failed   []string
+		wantErr  bool
+	}{
+		{
+			name:     "accepted",
+			refspecs: tagRefspecs("b", "c"),
+			policy:   RetryPolicy{Retries: 3, Delay: time.Millisecond},
+			pushes:   [][]string{tagRefspecs("b", "c")},
+			delays:   []time.Duration{0},
+			pushed:   tagRefspecs("b", "c"),
+		},
+		{
+			name:     "retries only the locked ref",
+			refspecs: tagRefspecs("a", "b", "c"),
+			failures: map[string][]string{tagRefspecs("b")[0]: {"cannot lock ref"}},
+			policy:   RetryPolicy{Retries: 3, Delay: time.Millisecond},
+			pushes:   [][]string{tagRefspecs("a", "b", "c"), tagRefspecs("b")},
+			delays:   []time.Duration{time.Millisecond, 0},
+			pushed:   tagRefspecs("c", "b"),
+			failed:   tagRefspecs("a"),
+			wantErr:  true,
+		},
+		{
+			name:     "backs off up to the maximum delay",
+			refspecs: tagRefspecs("b"),
+			failures: map[string][]string{tagRefspecs("b")[0]: {"cannot lock ref", "cannot lock ref", "cannot lock ref"}},
+			policy:   RetryPolicy{Retries: 3, Delay: time.Millisecond, MaxDelay: 3 * time.Millisecond},
+			pushes:   [][]string{tagRefspecs("b"), tagRefspecs("b"), tagRefspecs("b"), tagRefspecs("b")},
+			delays:   []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, 0},
+			pushed:   tagRefspecs("b"),
+		},
+		{
+			name:     "gives up after the retries",
+			refspecs: tagRefspecs("b", "c"),
+			failures: map[string][]string{tagRefspecs("b")[0]: {"cannot lock ref", "cannot lock ref", "cannot lock ref"}},
+			policy:   RetryPolicy{Retries: 2, Delay: time.Millisecond},
+			pushes:   [][]string{tagRefspecs("b", "c"), tagRefspecs("b"), tagRefspecs("b")},
+			delays:   []time.Duration{time.Millisecond, 2 * time.Millisecond, 0},
+			pushed:   tagRefspecs("c"),
+			failed:   tagRefspecs("b"),
+			wantErr:  true,
+		},
+		{
+			name:     "does not retry without retries",
+			refspecs: tagRefspecs("b"),
+			failures: map[string][]string{tagRefspecs("b")[0]: {"cannot lock ref"}},
+			policy:   RetryPolicy{Delay: time.Millisecond},
+			pushes:   [][]string{tagRefspecs("b")},
+			delays:   []time.Duration{0},
+			failed:   tagRefspecs("b"),
+			wantErr:  true,
+		},
+		{
+			name:     "retries a push that failed as a whole",
+			refspecs: tagRefspecs("b", "c"),
+			err:      &GitError{Op: "push", ExitCode: 128, Stderr: "fatal: the remote end hung up unexpectedly"},
+			policy:   RetryPolicy{Retries: 1, Delay: time.Millisecond},
+			pushes:   [][]string{tagRefspecs("b", "c"), tagRefspecs("b", "c")},
+			delays:   []time.Duration{time.Millisecond, 0},
+			failed:   tagRefspecs("b", "c"),
+			wantErr:  true,
+		},
+		{
+			name:     "does not retry permanent failures",
+			refspecs: tagRefspecs("b"),
+			err:      &GitError{Op: "push", ExitCode: 128, Stderr: "remote: Permission denied"},
+			policy:   RetryPolicy{Retries: 3, Delay: time.Millisecond},
+			pushes:   [][]string{tagRefspecs("b")},
+			delays:   []time.Duration{0},
+			failed:   tagRefspecs("b"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, remote := newFlakyBackend(t)
+			for refspec, reasons := range tt.failures {
+				backend.failures[refspec] = reasons
+			}
+			backend.err = tt.err
+
+			result, err := PushWithRetry(context.Background(), backend, "origin", tt.refspecs, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PushWithRetry() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(backend.pushes, tt.pushes) {
+				t.Errorf("pushed %q, want %q", backend.pushes, tt.pushes)
+			}
+
+			var delays []time.Duration
+			for i, attempt := range result.Attempts {
+				delays = append(delays, attempt.Delay)
+				if !reflect.DeepEqual(attempt.Refspecs, tt.pushes[i]) {
+					t.Errorf("attempt %d pushed %q, want %q", i+1, attempt.Refspecs, tt.pushes[i])
+				}
+			}
+			if !reflect.DeepEqual(delays, tt.delays) {
+				t.Errorf("delays = %v, want %v", delays, tt.delays)
+			}
+			if !reflect.DeepEqual(result.Pushed, tt.pushed) {
+				t.Errorf("Pushed = %q, want %q", result.Pushed, tt.pushed)
+			}
+			var failed []string
+			for _, rejection := range result.Failed {
+				failed = append(failed, rejection.Refspec)
+			}
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("Failed = %q, want %q", failed, tt.failed)
+			}
+
+			// The refs reported as pushed are on the remote
+			for _, refspec := range result.Pushed {
+				_, ref, _ := strings.Cut(refspec, ":")
+				if ok, err := remote.RefExists(context.Background(), ref); err != nil || !ok {
+					t.Errorf("%s is not on the remote: %v", ref, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPushWithRetryStopsBackoffWhenCancelled(t *testing.T) {
+	backend, _ := newFlakyBackend(t)
+	backend.err = errors.New("connection reset by peer")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := PushWithRetry(ctx, backend, "origin", tagRefspecs("b", "c"), RetryPolicy{Retries: 3, Delay: time.Hour})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("PushWithRetry() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("PushWithRetry() returned after %v, want the backoff interrupted", elapsed)
+	}
+	if len(backend.pushes) != 1 || len(result.Attempts) != 1 {
+		t.Errorf("pushed %d times with %d attempts, want 1", len(backend.pushes), len(result.Attempts))
+	}
+	if len(result.Failed) != 2 || result.Failed[0].Reason != context.Canceled.Error() {
+		t.Errorf("Failed = %+v, want both refs failed with %q", result.Failed, context.Canceled)
+	}
+}
+
+func TestPushWithRetryDoesNotRetryWhenCancelled(t *testing.T) {
+	backend, _ := newFlakyBackend(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	backend.err = context.Canceled
+	cancel()
+
+	result, err := PushWithRetry(ctx, backend, "origin", tagRefspecs("b"), RetryPolicy{Retries: 3, Delay: time.Millisecond})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("PushWithRetry() error = %v, want context.Canceled", err)
+	}
+	if len(backend.pushes) != 1 || len(result.Attempts) != 1 || result.Attempts[0].Retry != nil {
+		t.Errorf("attempts = %+v, want a single one without retry", result.Attempts)
+	}
+}
+
+func TestPushError(t *testing.T) {
+	gitErr := &GitError{Op: "push", ExitCode: 1, Stderr: "error: failed to push some refs"}
+
+	tests := []struct {
+		name     string
+		refspecs []string
+		output   string
+		err      error
+		want     []RefRejection // nil when err is returned unchanged
+	}{
+		{
+			name:     "not a git error",
+			refspecs: tagRefspecs("a"),
+			output:   "!\trefs/tags/a:refs/tags/a\t[rejected] (already exists)",
+			err:      context.DeadlineExceeded,
+		},
+		{
+			name:     "no per-ref results",
+			refspecs: tagRefspecs("a"),
+			output:   "fatal: could not read from remote repository",
+			err:      gitErr,
+		},
+		{
+			name:     "some refs rejected",
+			refspecs: tagRefspecs("a", "b", "c"),
+			output: "To /tmp/remote.git\n" +
+				"!\trefs/tags/a:refs/tags/a\t[rejected] (already exists)\n" +
+				"*\trefs/tags/b:refs/tags/b\t[new tag]\n" +
+				"!\trefs/tags/c:refs/tags/c\t[remote rejected] (pre-receive hook declined)\n" +
+				"Done",
+			err: gitErr,
+			want: []RefRejection{
+				{Refspec: "refs/tags/a:refs/tags/a", Reason: "already exists"},
+				{Refspec: "refs/tags/c:refs/tags/c", Reason: "pre-receive hook declined"},
+			},
+		},
+		{
+			name:     "force and short refspecs",
+			refspecs: []string{"+abc123:refs/tags/a", "refs/tags/b"},
+			output: "!\tabc123:refs/tags/a\t[remote rejected] (failed to update ref)\n" +
+				"!\trefs/tags/b:refs/tags/b\t[rejected] (already exists)",
+			err: gitErr,
+			want: []RefRejection{
+				{Refspec: "+abc123:refs/tags/a", Reason: "failed to update ref"},
+				{Refspec: "refs/tags/b", Reason: "already exists"},
+			},
+		},
+		{
+			name:     "locked ref",
+			refspecs: tagRefspecs("a"),
+			output:   "!\trefs/tags/a:refs/tags/a\t[remote rejected] (failed to update ref)",
+			err: &GitError{Op: "push", ExitCode: 1, Stderr: "remote: error: cannot lock ref 'refs/tags/a': " +
+				"Unable to create '/srv/repo.git/refs/tags/a.lock': File exists."},
+			want: []RefRejection{{Refspec: "refs/tags/a:refs/tags/a", Reason: "cannot lock ref"}},
+		},
+		{
+			name:     "unknown ref",
+			refspecs: tagRefspecs("a"),
+			output:   "!\trefs/tags/z:refs/tags/z\t[rejected] (already exists)",
+			err:      gitErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pushError(tt.refspecs, tt.output, tt.err)
+			if tt.want == nil {
+				if err != tt.err {
+					t.Errorf("pushError() = %v, want %v unchanged", err, tt.err)
+				}
+				return
+			}
+
+			var pushErr *PushError
+			if !errors.As(err, &pushErr) {
+				t.Fatalf("pushError() = %v, want a *PushError", err)
+			}
+			if !reflect.DeepEqual(pushErr.Rejected, tt.want) {
+				t.Errorf("Rejected = %+v, want %+v", pushErr.Rejected, tt.want)
+			}
+			if pushErr.Err != tt.err {
+				t.Errorf("Err = %v, want %v", pushErr.Err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package rtag
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// TagFileName is the name of the file listing the tags of a project
+const TagFileName = ".rtag"
+
+// Markers starting the group and dependency lines of a .rtag file. Other
+// lines are tag names, as they always were.
+const (
+	groupMarker      = "[group]"
+	dependencyMarker = "[depends]"
+)
+
+// Group is a named set of tags, written in .rtag as "[group] name: tag1, tag2"
+type Group struct {
+	Name string
+	Tags []string
+}
+
+// Dependency declares the tags a tag depends on, written in .rtag as "[depends] tag -> dep1, dep2"
+type Dependency struct {
+	Tag       string
+	DependsOn []string
+}
+
+// TagFile is the parsed content of a .rtag file
+type TagFile struct {
+	Tags         []string
+	Groups       []Group
+	Dependencies []Dependency
+}
+
+// ReadTagFile parses a .rtag file. A missing file has no tags. A marked line
+// without its separator is an error rather than a tag.
+func ReadTagFile(path string) (TagFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return TagFile{Tags: []string{}}, nil
+		}
+		return TagFile{}, err
+	}
+	defer file.Close()
+
+	var content TagFile
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// Dependency: [depends] tag -> dep1, dep2
+		if rest, ok := strings.CutPrefix(line, dependencyMarker); ok {
+			tag, deps, found := strings.Cut(rest, "->")
+			if !found || strings.TrimSpace(tag) == "" {
+				return TagFile{}, &NameError{Name: line, Err: ErrInvalidTagFileLine}
+			}
+			content.Dependencies = append(content.Dependencies, Dependency{
+				Tag:       strings.TrimSpace(tag),
+				DependsOn: splitList(deps),
+			})
+			continue
+		}
+
+		// Group: [group] name: tag1, tag2
+		if rest, ok := strings.CutPrefix(line, groupMarker); ok {
+			name, members, found := strings.Cut(rest, ":")
+			if !found || strings.TrimSpace(name) == "" {
+				return TagFile{}, &NameError{Name: line, Err: ErrInvalidTagFileLine}
+			}
+			content.Groups = append(content.Groups, Group{
+				Name: strings.TrimSpace(name),
+				Tags: splitList(members),
+			})
+			continue
+		}
+
+		content.Tags = append(content.Tags, line)
+	}
+
+	return content, scanner.Err()
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ErrTagFileLocked is returned when another process keeps a .rtag file locked
+var ErrTagFileLocked = errors.New("tag file is locked by another process")
+
+// tagFileLockTimeout is how long a write waits for another process to release the lock
+const tagFileLockTimeout = 10 * time.Second
+
+// tagFileLockStaleAge is the age after which a lock whose holder cannot be
+// checked is taken over. The lock is only held while the small .rtag file is
+// read and rewritten, so an older one was left behind by a process that crashed.
+const tagFileLockStaleAge = tagFileLockTimeout / 2
+
+// WriteTagFile replaces a .rtag file atomically, a crash leaves either the old
+// or the new content
+func WriteTagFile(path string, content TagFile) error {
+	lock, err := lockTagFile(path)
+	if err != nil {
+		return err
+	}
+	return commitTagFile(lock, path, content)
+}
+
+// UpdateTagFile applies update to a .rtag file. The file stays locked from the
+// read to the write, so concurrent updates are applied one after the other
+// instead of losing each other's changes. Nothing is written if update fails.
+func UpdateTagFile(path string, update func(*TagFile) error) error {
+	lock, err := lockTagFile(path)
+	if err != nil {
+		return err
+	}
+
+	content, err := ReadTagFile(path)
+	if err == nil {
+		err = update(&content)
+	}
+	if err != nil {
+		lock.Close()
+		os.Remove(lock.Name())
+		return err
+	}
+
+	return commitTagFile(lock, path, content)
+}
+
+// lockTagFile creates path.lock exclusively, waiting while another process
+// holds it. Like git does for its own files, the new content is written to
+// the lock file, which is then renamed over path. Until then the lock records
+// the pid and host of its holder, so that a lock left behind by a crash is
+// reclaimed instead of blocking every later update.
+func lockTagFile(path string) (*os.File, error) {
+	deadline := time.Now().Add(tagFileLockTimeout)
+	for {
+		lock, err := os.OpenFile(path+".lock", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if err == nil {
+			host, _ := os.Hostname()
+			if _, err := fmt.Fprintf(lock, "%d %s\n", os.Getpid(), host); err != nil {
+				lock.Close()
+				os.Remove(lock.Name())
+				return nil, err
+			}
+			return lock, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if reclaimTagFileLock(path + ".lock") {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s.lock: %w", path, ErrTagFileLocked)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// reclaimTagFileLock removes a lock whose holder is no longer running, and
+// reports whether it did. The pid can only be checked for locks taken on this
+// host; locks of another host, or whose holder is unknown because it is
+// already writing the new content, are removed once older than
+// tagFileLockStaleAge.
+func reclaimTagFileLock(name string) bool {
+	info, err := os.Stat(name)
+	if err != nil {
+		// Released in the meantime
+		return os.IsNotExist(err)
+	}
+
+	stale := time.Since(info.ModTime()) > tagFileLockStaleAge
+	content, _ := os.ReadFile(name)
+	var pid int
+	var owner string
+	host, _ := os.Hostname()
+	if n, _ := fmt.Sscanf(string(content), "%d %s\n", &pid, &owner); n == 2 && owner == host {
+		stale = !processRunning(pid)
+	}
+	if !stale {
+		return false
+	}
+
+	// Only remove the lock that was judged, not one taken since
+	if current, err := os.Stat(name); err != nil || !os.SameFile(info, current) {
+		return os.IsNotExist(err)
+	}
+	err = os.Remove(name)
+	return err == nil || os.IsNotExist(err)
+}
+
+// processRunning reports whether a process with the pid exists
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Finding a process opens it on Windows and always succeeds elsewhere
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
+
+// commitTagFile writes content to the lock file and renames it over path,
+// keeping the permissions of the existing file
+func commitTagFile(lock *os.File, path string, content TagFile) error {
+	// Replace the pid and host written by lockTagFile
+	err := lock.Truncate(0)
+	if err == nil {
+		_, err = lock.Seek(0, io.SeekStart)
+	}
+	if err == nil {
+		err = writeTagFile(lock, content)
+	}
+	if info, statErr := os.Stat(path); err == nil && statErr == nil {
+		err = lock.Chmod(info.Mode().Perm())
+	}
+	if err == nil {
+		err = lock.Sync()
+	}
+	if closeErr := lock.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(lock.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(lock.Name())
+	}
+	return err
+}
+
+// writeTagFile writes the lines of a .rtag file
+func writeTagFile(file io.Writer, content TagFile) error {
+	for _, tag := range content.Tags {
+		if _, err := fmt.Fprintln(file, tag); err != nil {
+			return err
+		}
+	}
+
+	// Groups and dependencies follow the tags, empty ones are dropped
+	var lines []string
+	for _, group := range content.Groups {
+		if len(group.Tags) > 0 {
+			lines = append(lines, fmt.Sprintf("%s %s: %s", groupMarker, group.Name, strings.Join(group.Tags, ", ")))
+		}
+	}
+	for _, dependency := range content.Dependencies {
+		if len(dependency.DependsOn) > 0 {
+			lines = append(lines, fmt.Sprintf("%s %s -> %s", dependencyMarker, dependency.Tag, strings.Join(dependency.DependsOn, ", ")))
+		}
+	}
+
+	if len(lines) > 0 && len(content.Tags) > 0 {
+		if _, err := fmt.Fprintln(file); err != nil {
+			return err
+		}
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(file, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateTagName rejects names that would be read back as a group or selector
+func ValidateTagName(tag string) error {
+	if strings.HasPrefix(tag, "@") || strings.ContainsAny(tag, ":,<>*?[] \t") {
+		return &NameError{Name: tag, Err: ErrInvalidTagName}
+	}
+	return nil
+}
+
+// Contains reports whether tag is listed in the file
+func (f TagFile) Contains(tag string) bool {
+	for _, t := range f.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Add appends tags that are valid and not listed yet
+func (f *TagFile) Add(tags ...string) error {
+	for i, tag := range tags {
+		if err := ValidateTagName(tag); err != nil {
+			return err
+		}
+		if f.Contains(tag) || containsString(tags[:i], tag) {
+			return &NameError{Name: tag, Err: ErrTagExists}
+		}
+	}
+
+	f.Tags = append(f.Tags, tags...)
+	return nil
+}
+
+// Remove deletes tags from the file, including from its groups and dependencies
+func (f *TagFile) Remove(tags ...string) error {
+	for _, tag := range tags {
+		if !f.Contains(tag) {
+			return &NameError{Name: tag, Err: ErrTagNotFound}
+		}
+	}
+
+	for _, tag := range tags {
+		f.Tags = withoutTag(f.Tags, tag)
+		for i, group := range f.Groups {
+			f.Groups[i].Tags = withoutTag(group.Tags, tag)
+		}
+
+		var dependencies []Dependency
+		for _, dependency := range f.Dependencies {
+			if dependency.Tag != tag {
+				dependency.DependsOn = withoutTag(dependency.DependsOn, tag)
+				dependencies = append(dependencies, dependency)
+			}
+		}
+		f.Dependencies = dependencies
+	}
+	return nil
+}
+
+// withoutTag returns tags with every occurrence of tag removed
+func withoutTag(tags []string, tag string) []string {
+	result := []string{}
+	for _, t := range tags {
+		if t != tag {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
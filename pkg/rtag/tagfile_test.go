@@ -0,0 +1,124 @@
+package rtag
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUpdateTagFileReclaimsStaleLocks(t *testing.T) {
+	// A pid that is certainly not running any more
+	finished := exec.Command(os.Args[0], "-test.run=^$")
+	if err := finished.Run(); err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		age     time.Duration
+	}{
+		{"holder exited", formatPid(finished.Process.Pid, host), 0},
+		{"old lock of another host", formatPid(os.Getpid(), "elsewhere"), 2 * tagFileLockStaleAge},
+		{"old lock without holder", "api\n", 2 * tagFileLockStaleAge},
+	} {
+		path := filepath.Join(t.TempDir(), TagFileName)
+		if err := os.WriteFile(path+".lock", []byte(tc.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		when := time.Now().Add(-tc.age)
+		if err := os.Chtimes(path+".lock", when, when); err != nil {
+			t.Fatal(err)
+		}
+
+		start := time.Now()
+		err := UpdateTagFile(path, func(content *TagFile) error { return content.Add("api") })
+		if err != nil || time.Since(start) > tagFileLockStaleAge {
+			t.Errorf("%s: UpdateTagFile() = %v after %v", tc.name, err, time.Since(start))
+		}
+		if content, err := ReadTagFile(path); err != nil || !reflect.DeepEqual(content.Tags, []string{"api"}) {
+			t.Errorf("%s: ReadTagFile() = %v, %v", tc.name, content.Tags, err)
+		}
+	}
+}
+
+func TestReclaimTagFileLock(t *testing.T) {
+	finished := exec.Command(os.Args[0], "-test.run=^$")
+	if err := finished.Run(); err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+	old := 2 * tagFileLockStaleAge
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		age     time.Duration
+		want    bool
+	}{
+		{"holder exited", formatPid(finished.Process.Pid, host), 0, true},
+		{"holder running", formatPid(os.Getpid(), host), 0, false},
+		// A live holder keeps its lock however long it takes
+		{"old lock of a running holder", formatPid(os.Getpid(), host), old, false},
+		// The pid of another host cannot be checked
+		{"holder of another host", formatPid(finished.Process.Pid, "elsewhere"), 0, false},
+		{"old lock of another host", formatPid(os.Getpid(), "elsewhere"), old, true},
+		{"holder writing the content", "api\ncron\n", 0, false},
+		{"old lock without holder", "api\n", old, true},
+		{"empty lock", "", 0, false},
+	} {
+		name := filepath.Join(t.TempDir(), TagFileName+".lock")
+		if err := os.WriteFile(name, []byte(tc.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		when := time.Now().Add(-tc.age)
+		if err := os.Chtimes(name, when, when); err != nil {
+			t.Fatal(err)
+		}
+
+		got := reclaimTagFileLock(name)
+		_, err := os.Stat(name)
+		if got != tc.want || got != os.IsNotExist(err) {
+			t.Errorf("%s: reclaimTagFileLock() = %v with the lock removed %v, want %v", tc.name, got, os.IsNotExist(err), tc.want)
+		}
+	}
+}
+
+func TestUpdateTagFileWaitsForLiveLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), TagFileName)
+	lock, err := lockTagFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- UpdateTagFile(path, func(content *TagFile) error { return content.Add("cron") })
+	}()
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("UpdateTagFile() = %v while the file was locked", err)
+	default:
+	}
+
+	if err := commitTagFile(lock, path, TagFile{Tags: []string{"api"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if content, err := ReadTagFile(path); err != nil || !reflect.DeepEqual(content.Tags, []string{"api", "cron"}) {
+		t.Errorf("ReadTagFile() = %v, %v", content.Tags, err)
+	}
+}
+
+// formatPid returns the content lockTagFile records in a lock
+func formatPid(pid int, host string) string {
+	return fmt.Sprintf("%d %s\n", pid, host)
+}
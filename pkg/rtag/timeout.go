@@ -0,0 +1,160 @@
+package rtag
+
+import (
+	"context"
+	"time"
+)
+
+// timeoutBackend bounds every operation of another backend
+type timeoutBackend struct {
+	backend Backend
+	timeout time.Duration
+}
+
+// WithTimeout returns a backend that cancels each operation of backend after
+// timeout, so a git process waiting on the network or on credentials cannot
+// block forever. A timeout of zero or less returns backend unchanged.
+func WithTimeout(backend Backend, timeout time.Duration) Backend {
+	if timeout <= 0 {
+		return backend
+	}
+	return &timeoutBackend{backend: backend, timeout: timeout}
+}
+
+func (b *timeoutBackend) Root(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.Root(ctx)
+}
+
+func (b *timeoutBackend) Branch(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.Branch(ctx)
+}
+
+func (b *timeoutBackend) ChangedFiles(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.ChangedFiles(ctx)
+}
+
+func (b *timeoutBackend) Identity(ctx context.Context) (Signature, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.Identity(ctx)
+}
+
+func (b *timeoutBackend) ResolveRef(ctx context.Context, ref string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.ResolveRef(ctx, ref)
+}
+
+func (b *timeoutBackend) RefExists(ctx context.Context, ref string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.RefExists(ctx, ref)
+}
+
+func (b *timeoutBackend) Log(ctx context.Context, since, until string) ([]CommitInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.Log(ctx, since, until)
+}
+
+func (b *timeoutBackend) ListTags(ctx context.Context) ([]TagRef, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.ListTags(ctx)
+}
+
+func (b *timeoutBackend) TagInfo(ctx context.Context, name string) (TagInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.TagInfo(ctx, name)
+}
+
+func (b *timeoutBackend) CreateTag(ctx context.Context, name, target string, opts TagOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.CreateTag(ctx, name, target, opts)
+}
+
+func (b *timeoutBackend) UpdateTags(ctx context.Context, updates []TagUpdate) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.UpdateTags(ctx, updates)
+}
+
+func (b *timeoutBackend) DeleteTags(ctx context.Context, names []string) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.DeleteTags(ctx, names)
+}
+
+func (b *timeoutBackend) DeleteBlobRef(ctx context.Context, ref string) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.DeleteBlobRef(ctx, ref)
+}
+
+func (b *timeoutBackend) WriteBlobRef(ctx context.Context, ref, content string) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.WriteBlobRef(ctx, ref, content)
+}
+
+func (b *timeoutBackend) ReadBlobRefs(ctx context.Context, prefix string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.ReadBlobRefs(ctx, prefix)
+}
+
+func (b *timeoutBackend) PushRefs(ctx context.Context, remote string, refspecs []string) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.PushRefs(ctx, remote, refspecs)
+}
+
+func (b *timeoutBackend) CheckPush(ctx context.Context, remote string) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.CheckPush(ctx, remote)
+}
+
+func (b *timeoutBackend) WriteBlob(ctx context.Context, content string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.WriteBlob(ctx, content)
+}
+
+func (b *timeoutBackend) ReadBlob(ctx context.Context, hash string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.ReadBlob(ctx, hash)
+}
+
+func (b *timeoutBackend) UpdateRemoteRef(ctx context.Context, remote, ref, old, hash string) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.UpdateRemoteRef(ctx, remote, ref, old, hash)
+}
+
+func (b *timeoutBackend) RemoteURL(ctx context.Context, remote string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.RemoteURL(ctx, remote)
+}
+
+func (b *timeoutBackend) RemoteRefs(ctx context.Context, remote, prefix string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.RemoteRefs(ctx, remote, prefix)
+}
+
+func (b *timeoutBackend) FetchRefs(ctx context.Context, remote string, refs []string) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.backend.FetchRefs(ctx, remote, refs)
+}
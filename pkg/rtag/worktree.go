@@ -0,0 +1,478 @@
+package rtag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+)
+
+// indexEntry is a path staged in the index
+type indexEntry struct {
+	path         string
+	mode         uint32
+	hash         string
+	size         uint32
+	mtime        [2]uint32 // seconds and nanoseconds
+	stage        int       // 0, or 1 to 3 for the sides of a conflict
+	skipWorktree bool
+	intentToAdd  bool
+}
+
+// Index entry modes
+const (
+	modeRegular    = 0100644
+	modeExecutable = 0100755
+	modeSymlink    = 0120000
+	modeGitlink    = 0160000
+	modeTree       = 040000
+)
+
+// changedFiles counts the paths git status --porcelain would list: paths
+// staged or modified since HEAD and untracked paths that are not ignored,
+// an untracked directory counting once. Like git, a file whose size and
+// modification time match the index is not read; other files are compared
+// byte for byte, without running clean filters or line ending conversion.
+func (s *fileStorage) changedFiles() (int, error) {
+	if err := s.open(); err != nil {
+		return 0, err
+	}
+	if s.workDir == "" {
+		return 0, fmt.Errorf("%s: bare repository has no working tree", s.gitDir)
+	}
+
+	indexPath := filepath.Join(s.gitDir, "index")
+	entries, err := readIndex(indexPath)
+	if err != nil {
+		return 0, err
+	}
+	indexInfo, _ := os.Stat(indexPath)
+
+	tree := make(map[string]indexEntry)
+	if head, err := (&objectBackend{storage: s}).lookupRef("HEAD"); err == nil {
+		if err := s.readTree(head, "", tree); err != nil {
+			return 0, err
+		}
+	} else if !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+
+	changed := make(map[string]bool)
+	tracked := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		tracked[entry.path] = true
+		if entry.stage != 0 || entry.intentToAdd {
+			changed[entry.path] = true
+			continue
+		}
+
+		if committed, ok := tree[entry.path]; !ok || committed.hash != entry.hash || committed.mode != entry.mode {
+			changed[entry.path] = true
+		}
+		if !entry.skipWorktree && entry.mode != modeGitlink {
+			modified, err := s.worktreeModified(entry, indexInfo)
+			if err != nil {
+				return 0, err
+			}
+			if modified {
+				changed[entry.path] = true
+			}
+		}
+	}
+	for p := range tree {
+		if !tracked[p] {
+			changed[p] = true
+		}
+	}
+
+	rules := s.excludeRules()
+	untracked, _, err := s.countUntracked("", tracked, rules)
+	if err != nil {
+		return 0, err
+	}
+	return len(changed) + untracked, nil
+}
+
+// readIndex parses the entries of a version 2, 3 or 4 index file. A missing
+// index has no entries.
+func readIndex(path string) ([]indexEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	corrupt := fmt.Errorf("%s: corrupt index", path)
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, corrupt
+	}
+	version := binary.BigEndian.Uint32(data[4:])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("%s: index version %d: %w", path, version, ErrUnsupported)
+	}
+	count := int(binary.BigEndian.Uint32(data[8:]))
+
+	entries := make([]indexEntry, 0, count)
+	rest := data[12:]
+	previous := ""
+	for i := 0; i < count; i++ {
+		if len(rest) < 62 {
+			return nil, corrupt
+		}
+		entry := indexEntry{
+			mtime: [2]uint32{binary.BigEndian.Uint32(rest[8:]), binary.BigEndian.Uint32(rest[12:])},
+			mode:  binary.BigEndian.Uint32(rest[24:]),
+			size:  binary.BigEndian.Uint32(rest[36:]),
+			hash:  hex.EncodeToString(rest[40:60]),
+		}
+		flags := binary.BigEndian.Uint16(rest[60:])
+		entry.stage = int(flags>>12) & 3
+		length := 62
+		if flags&0x4000 != 0 && version >= 3 {
+			if len(rest) < 64 {
+				return nil, corrupt
+			}
+			extended := binary.BigEndian.Uint16(rest[62:])
+			entry.skipWorktree = extended&0x4000 != 0
+			entry.intentToAdd = extended&0x2000 != 0
+			length = 64
+		}
+		rest = rest[length:]
+
+		if version == 4 {
+			// The name replaces the end of the previous one: the number of
+			// bytes to strip, encoded like pack offsets, then the new suffix
+			if len(rest) == 0 {
+				return nil, corrupt
+			}
+			c := rest[0]
+			rest = rest[1:]
+			strip := int(c & 0x7f)
+			for c&0x80 != 0 {
+				if len(rest) == 0 {
+					return nil, corrupt
+				}
+				c = rest[0]
+				rest = rest[1:]
+				strip = (strip+1)<<7 | int(c&0x7f)
+			}
+			end := bytes.IndexByte(rest, 0)
+			if end < 0 || strip > len(previous) {
+				return nil, corrupt
+			}
+			entry.path = previous[:len(previous)-strip] + string(rest[:end])
+			rest = rest[end+1:]
+		} else {
+			end := bytes.IndexByte(rest, 0)
+			if end < 0 {
+				return nil, corrupt
+			}
+			entry.path = string(rest[:end])
+			// Entries are padded with 1 to 8 NUL bytes to a multiple of 8
+			padded := (length + end + 8) &^ 7
+			if padded-length > len(rest) {
+				return nil, corrupt
+			}
+			rest = rest[padded-length:]
+		}
+		previous = entry.path
+
+		// Sparse directory entries stand for whole trees outside the sparse checkout
+		if entry.mode != modeTree {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// readTree adds the files of a tree, or of the tree of a commit, to files keyed by path
+func (s *fileStorage) readTree(hash, prefix string, files map[string]indexEntry) error {
+	kind, data, err := s.readObject(hash)
+	if err != nil {
+		return err
+	}
+	if kind == "commit" {
+		return s.readTree(parseObject(data).header("tree"), prefix, files)
+	}
+	if kind != "tree" {
+		return fmt.Errorf("%s is a %s, not a tree", hash, kind)
+	}
+
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		end := bytes.IndexByte(data, 0)
+		if space < 0 || end < space || len(data) < end+21 {
+			return fmt.Errorf("tree %s: corrupt entry", hash)
+		}
+		var mode uint32
+		fmt.Sscanf(string(data[:space]), "%o", &mode)
+		name := prefix + string(data[space+1:end])
+		id := hex.EncodeToString(data[end+1 : end+21])
+		data = data[end+21:]
+
+		if mode == modeTree {
+			if err := s.readTree(id, name+"/", files); err != nil {
+				return err
+			}
+			continue
+		}
+		files[name] = indexEntry{path: name, mode: mode, hash: id}
+	}
+	return nil
+}
+
+// worktreeModified reports whether the working tree file of an entry differs from the index
+func (s *fileStorage) worktreeModified(entry indexEntry, index os.FileInfo) (bool, error) {
+	info, err := os.Lstat(filepath.Join(s.workDir, filepath.FromSlash(entry.path)))
+	// ENOTDIR: a file replaced one of the directories of the path
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var mode uint32 = modeRegular
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		mode = modeSymlink
+	case !info.Mode().IsRegular():
+		return true, nil
+	case info.Mode()&0111 != 0 && s.settings["core.filemode"] != "false":
+		mode = modeExecutable
+	}
+	if s.settings["core.filemode"] == "false" && mode != modeSymlink && entry.mode != modeSymlink {
+		mode = entry.mode
+	}
+	if mode != entry.mode {
+		return true, nil
+	}
+
+	// Trust unchanged stat data, unless the file may have been modified in
+	// the same second the index was written
+	mtime := info.ModTime()
+	if uint32(info.Size()) == entry.size && uint32(mtime.Unix()) == entry.mtime[0] && uint32(mtime.Nanosecond()) == entry.mtime[1] &&
+		(index == nil || mtime.Before(index.ModTime())) {
+		return false, nil
+	}
+
+	var content []byte
+	if mode == modeSymlink {
+		target, err := os.Readlink(filepath.Join(s.workDir, filepath.FromSlash(entry.path)))
+		if err != nil {
+			return false, err
+		}
+		content = []byte(filepath.ToSlash(target))
+	} else if content, err = os.ReadFile(filepath.Join(s.workDir, filepath.FromSlash(entry.path))); err != nil {
+		return false, err
+	}
+	return hashObject("blob", content) != entry.hash, nil
+}
+
+// ignoreRule is one pattern of a .gitignore or exclude file
+type ignoreRule struct {
+	base     string // directory of the .gitignore, "" for the top level
+	pattern  *regexp.Regexp
+	anchored bool // matched against the path below base rather than the name
+	dirOnly  bool
+	negate   bool
+}
+
+// excludeRules returns the rules of the global excludes file and .git/info/exclude
+func (s *fileStorage) excludeRules() []ignoreRule {
+	var rules []ignoreRule
+	excludes := s.settings["core.excludesfile"]
+	if excludes == "" {
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			excludes = filepath.Join(xdg, "git", "ignore")
+		} else if home, err := os.UserHomeDir(); err == nil {
+			excludes = filepath.Join(home, ".config", "git", "ignore")
+		}
+	} else if rest, ok := strings.CutPrefix(excludes, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			excludes = filepath.Join(home, rest)
+		}
+	}
+	if excludes != "" {
+		rules = append(rules, readIgnoreFile(excludes, "")...)
+	}
+	return append(rules, readIgnoreFile(filepath.Join(s.commonDir, "info", "exclude"), "")...)
+}
+
+// readIgnoreFile parses the patterns of an ignore file applying below base
+func readIgnoreFile(file, base string) []ignoreRule {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	var rules []ignoreRule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if line[0] == '!' {
+			rule.negate = true
+			line = line[1:]
+		} else if line[0] == '\\' {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		if pattern, err := regexp.Compile(ignorePattern(line)); err == nil {
+			rule.pattern = pattern
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// ignorePattern translates a gitignore glob into an anchored regular expression
+func ignorePattern(glob string) string {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			expr.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return expr.String()
+}
+
+// ignored reports whether the last rule matching a path ignores it
+func ignored(rules []ignoreRule, rel string, dir bool) bool {
+	result := false
+	for _, rule := range rules {
+		if rule.dirOnly && !dir {
+			continue
+		}
+		below := rel
+		if rule.base != "" {
+			var ok bool
+			if below, ok = strings.CutPrefix(rel, rule.base+"/"); !ok {
+				continue
+			}
+		}
+		subject := below
+		if !rule.anchored {
+			subject = path.Base(below)
+		}
+		if rule.pattern.MatchString(subject) {
+			result = !rule.negate
+		}
+	}
+	return result
+}
+
+// countUntracked counts the untracked paths below dir that are not ignored,
+// an untracked directory counting once. It also reports whether dir holds
+// any tracked path.
+func (s *fileStorage) countUntracked(dir string, tracked map[string]bool, rules []ignoreRule) (int, bool, error) {
+	full := filepath.Join(s.workDir, filepath.FromSlash(dir))
+	// Copy before appending so sibling directories do not share their rules
+	rules = append(rules[:len(rules):len(rules)], readIgnoreFile(filepath.Join(full, ".gitignore"), dir)...)
+
+	entries, err := os.ReadDir(full)
+	if err != nil {
+		return 0, false, err
+	}
+
+	count, hasTracked := 0, false
+	for _, entry := range entries {
+		rel := entry.Name()
+		if dir != "" {
+			rel = dir + "/" + rel
+		}
+		if dir == "" && entry.Name() == ".git" {
+			continue
+		}
+		if tracked[rel] {
+			hasTracked = true
+			continue
+		}
+
+		isDir := entry.IsDir()
+		if ignored(rules, rel, isDir) {
+			continue
+		}
+		if !isDir {
+			count++
+			continue
+		}
+
+		// A nested repository that is not a submodule is listed as a whole
+		if _, err := os.Lstat(filepath.Join(s.workDir, filepath.FromSlash(rel), ".git")); err == nil {
+			count++
+			continue
+		}
+
+		untracked, subTracked, err := s.countUntracked(rel, tracked, rules)
+		if err != nil {
+			return 0, false, err
+		}
+		switch {
+		case subTracked:
+			hasTracked = true
+			count += untracked
+		case untracked > 0:
+			count++
+		}
+	}
+	return count, hasTracked, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// checkReleasePolicy evaluates the release policy for releasing commit, HEAD
+// when it is empty, as tags and prints the violations. It returns false if
+// the release must not go ahead.
+func checkReleasePolicy(tags []string, commit string, policy rtag.Policy, now time.Time) bool {
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().PolicyCheckFailed+"\n", err)
+		return false
+	}
+
+	violations, err := project.CheckPolicy(runContext, policy, commit, tags, now)
+	if err != nil {
+		fmt.Printf(T().PolicyCheckFailed+"\n", localizePolicyError(err))
+		return false
+	}
+
+	if len(violations) == 0 {
+		return true
+	}
+
+	fmt.Println(T().PolicyViolated)
+	for _, violation := range violations {
+		fmt.Println(describeViolation(violation))
+	}
+	return false
+}
+
+// describeViolation explains a broken policy rule in the current language
+func describeViolation(v rtag.Violation) string {
+	switch v.Rule {
+	case rtag.RuleBranch:
+		return fmt.Sprintf(T().PolicyBranch, v.Actual, v.Allowed)
+	case rtag.RuleCleanTree:
+		return fmt.Sprintf(T().PolicyCleanTree, v.Actual)
+	case rtag.RuleMinInterval:
+		return fmt.Sprintf(T().PolicyMinInterval, v.Component, v.Actual, v.Tag, v.Allowed)
+	case rtag.RuleApprovals:
+		return fmt.Sprintf(T().PolicyApprovals, v.Actual, v.Allowed)
+	case rtag.RuleReleasers:
+		return fmt.Sprintf(T().PolicyReleasers, v.Actual, v.Allowed)
+	case rtag.RuleDays:
+		return fmt.Sprintf(T().PolicyDays, v.Actual, v.Allowed)
+	case rtag.RuleHours:
+		return fmt.Sprintf(T().PolicyHours, v.Actual, v.Allowed)
+	}
+	return fmt.Sprintf("  - %s: %s (%s)", v.Rule, v.Actual, v.Allowed)
+}
+
+// localizePolicyError translates invalid policy settings
+func localizePolicyError(err error) error {
+	var policyErr *rtag.PolicyError
+	if errors.As(err, &policyErr) {
+		return fmt.Errorf(T().PolicyInvalidSetting, policyErr.Rule, policyErr.Value)
+	}
+	return err
+}
+
+func runPolicyCheck(cmd *cobra.Command, args []string) {
+	content, err := readTagFile()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		exitCode = 1
+		return
+	}
+
+	tags := content.Tags
+	if len(args) > 0 {
+		if tags, err = resolveSelectors(content, args); err != nil {
+			fmt.Printf(T().SelectTagsFailed+"\n", err)
+			exitCode = 1
+			return
+		}
+	}
+
+	config, err := loadProjectConfig()
+	if err != nil {
+		fmt.Printf(T().LoadConfigFailed+"\n", err)
+		exitCode = 1
+		return
+	}
+
+	if !checkReleasePolicy(tags, "", config.Policy, time.Now()) {
+		exitCode = 1
+		return
+	}
+	fmt.Printf(T().PolicyPassed+"\n", strings.Join(tags, ", "))
+}
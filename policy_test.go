@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicyFailureExitStatus(t *testing.T) {
+	dir := newTestRepo(t, "api\n", `{"policy": {"branches": ["release/*"]}}`)
+
+	for _, args := range [][]string{{"policy", "check"}, {"push", "api"}} {
+		output, status := runRtag(t, dir, args...)
+		if status != 1 || !strings.Contains(output, "main") {
+			t.Errorf("rtag %s exited with %d, want 1:\n%s", strings.Join(args, " "), status, output)
+		}
+	}
+	if tags := runGit(t, dir, "tag"); tags != "" {
+		t.Errorf("a refused release created tags: %s", tags)
+	}
+
+	runGit(t, dir, "checkout", "-q", "-b", "release/1")
+	if output, status := runRtag(t, dir, "policy", "check"); status != 0 {
+		t.Errorf("rtag policy check exited with %d on an allowed branch:\n%s", status, output)
+	}
+	if output, status := runRtag(t, dir, "push", "api"); status != 0 || runGit(t, dir, "ls-remote", "--tags", "origin") == "" {
+		t.Errorf("rtag push exited with %d on an allowed branch:\n%s", status, output)
+	}
+}
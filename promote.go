@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// promoteAnnotationFormat is the annotation recorded on environment tags
+const promoteAnnotationFormat = "Promote %s to %s"
+
+func runPromote(cmd *cobra.Command, args []string) {
+	tag := args[0]
+	if promoteTo == "" {
+		fmt.Println(T().PromoteTargetRequired)
+		return
+	}
+
+	tags, err := readTags()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	if !containsTag(tags, tag) {
+		fmt.Printf(T().TagNotExistInFile+"\n", tag)
+		return
+	}
+
+	config, err := loadProjectConfig()
+	if err != nil {
+		fmt.Printf(T().LoadConfigFailed+"\n", err)
+		return
+	}
+
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().PromoteFailed+"\n", err)
+		return
+	}
+	envTemplate, err := environmentTemplate(project.Naming(), promoteTo)
+	if err != nil {
+		fmt.Printf(T().PromoteFailed+"\n", err)
+		return
+	}
+
+	source, err := promotionSource(config.Environments, promoteTo, tag)
+	if err != nil {
+		fmt.Printf(T().PromoteFailed+"\n", err)
+		return
+	}
+
+	current, err := listPrefixedTags(promoteTo, tag)
+	if err != nil {
+		fmt.Printf(T().ListReleasesFailed+"\n", err)
+		return
+	}
+
+	if len(current) > 0 && current[len(current)-1].Commit == source.Commit {
+		fmt.Printf(T().AlreadyPromoted+"\n", source.Tag, promoteTo, current[len(current)-1].Tag)
+		return
+	}
+
+	now := time.Now()
+	gitTag := envTemplate.Format(map[string]string{"timestamp": now.Format(rtag.TimestampFormat), "component": tag})
+	report := runRelease(releaseRun{
+		Components: []string{tag},
+		Time:       now,
+		Commit:     source.Commit,
+		TagName: func(component string) string {
+			return gitTag
+		},
+		// The changelog of a promotion starts at the previous promotion to the environment
+		History: func() (map[string][]rtag.Release, error) {
+			promoted, err := listPrefixedTags(promoteTo, tag)
+			return map[string][]rtag.Release{tag: promoted}, err
+		},
+		Create: func(config projectConfig, override string) ([]string, []string, bool) {
+			fmt.Printf(T().Promoting+"\n", source.Tag, promoteTo)
+
+			annotation := withFreezeOverride(fmt.Sprintf(promoteAnnotationFormat, source.Tag, promoteTo), override)
+			if err := createTag(gitTag, source.Commit, rtag.TagOptions{Message: annotation}); err != nil {
+				fmt.Printf(T().CreateTagFailed+"\n", gitTag, err)
+				return nil, nil, false
+			}
+			return []string{gitTag}, []string{rtag.TagRefspec(gitTag)}, true
+		},
+	})
+
+	if report.Pushed {
+		fmt.Printf(T().PromoteSuccess+"\n", tag, promoteTo, gitTag)
+	}
+}
+
+// environmentTemplate returns the template of the tags promoting to env. An
+// environment whose tags would be taken for release tags, such as "release"
+// with the default naming, is refused.
+func environmentTemplate(naming rtag.Template, env string) (rtag.Template, error) {
+	template, err := prefixedTemplate(env)
+	if err != nil {
+		return rtag.Template{}, err
+	}
+
+	sample := template.Format(map[string]string{"timestamp": time.Now().Format(rtag.TimestampFormat), "component": "component"})
+	if _, ok := naming.Match(sample); ok {
+		return rtag.Template{}, fmt.Errorf(T().EnvironmentTagsAreReleases, env)
+	}
+	return template, nil
+}
+
+// promotionSource returns the tag a component is promoted from: its latest
+// release for the first environment, otherwise its latest tag in the previous
+// environment, so that no environment can be skipped. Yanked releases are
+// skipped at every stage.
+func promotionSource(environments []string, env, component string) (rtag.Release, error) {
+	index := -1
+	for i, e := range environments {
+		if e == env {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		return rtag.Release{}, fmt.Errorf(T().UnknownEnvironment, env, strings.Join(environments, ", "))
+	}
+
+	if index == 0 {
+		releases, err := listReleases(component)
+		if err != nil {
+			return rtag.Release{}, err
+		}
+		for i := len(releases) - 1; i >= 0; i-- {
+			if !releases[i].Yanked {
+				return releases[i], nil
+			}
+		}
+		return rtag.Release{}, fmt.Errorf(T().NoReleasesFound, component)
+	}
+
+	previous := environments[index-1]
+	promoted, err := listPrefixedTags(previous, component)
+	if err != nil {
+		return rtag.Release{}, err
+	}
+
+	if len(promoted) == 0 {
+		return rtag.Release{}, fmt.Errorf(T().NotPromotedTo, component, previous)
+	}
+
+	releases, err := listReleases(component)
+	if err != nil {
+		return rtag.Release{}, err
+	}
+	yanked := yankedCommits(releases)
+	for i := len(promoted) - 1; i >= 0; i-- {
+		if !yanked[promoted[i].Commit] {
+			return promoted[i], nil
+		}
+	}
+	return rtag.Release{}, fmt.Errorf(T().OnlyYankedPromotions, component, previous)
+}
+
+// yankedCommits returns the commits whose releases were all yanked
+func yankedCommits(releases []rtag.Release) map[string]bool {
+	yanked := make(map[string]bool)
+	for _, release := range releases {
+		if release.Yanked {
+			if _, seen := yanked[release.Commit]; !seen {
+				yanked[release.Commit] = true
+			}
+		} else {
+			yanked[release.Commit] = false
+		}
+	}
+	return yanked
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// usePromotionRepo makes rtag functions run against an in-memory repository
+// with a commit per entry of commits, tagged with the entry's tags, and
+// yanks the releases in yanked. It returns the commit hashes.
+func usePromotionRepo(t *testing.T, commits [][]string, yanked ...string) []string {
+	t.Helper()
+	backend := useMemoryBackend(t)
+
+	var hashes []string
+	for i, tags := range commits {
+		hash, err := backend.Commit("change", time.Date(2024, 1, 1, i, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range tags {
+			if err := backend.CreateTag(runContext, tag, hash, rtag.TagOptions{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		hashes = append(hashes, hash)
+	}
+
+	for _, tag := range yanked {
+		if err := backend.WriteBlobRef(runContext, rtag.YankedRefPrefix+tag, "broken\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return hashes
+}
+
+func TestPromotionSource(t *testing.T) {
+	environments := []string{"staging", "production"}
+	commits := [][]string{
+		{"release-202401010000-api", "staging-202401020000-api", "release-202401010000-web"},
+		{"release-202402010000-api", "staging-202402020000-api"},
+		{"release-202403010000-api"},
+	}
+
+	tests := []struct {
+		name      string
+		yanked    []string
+		env       string
+		component string
+		want      string
+		wantErr   string // text expected in the error
+	}{
+		{"first environment", nil, "staging", "api", "release-202403010000-api", ""},
+		{"first environment skips yanked releases", []string{"release-202403010000-api"}, "staging", "api", "release-202402010000-api", ""},
+		{"first environment without releases", nil, "staging", "cron", "", "cron"},
+		{"first environment with only yanked releases", []string{"release-202401010000-web"}, "staging", "web", "", "web"},
+		{"previous environment", nil, "production", "api", "staging-202402020000-api", ""},
+		{"previous environment skips yanked releases", []string{"release-202402010000-api"}, "production", "api", "staging-202401020000-api", ""},
+		{"not promoted to the previous environment", nil, "production", "web", "", "staging"},
+		{"only yanked promotions", []string{"release-202401010000-api", "release-202402010000-api"}, "production", "api", "", "staging"},
+		{"unknown environment", nil, "qa", "api", "", "qa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes := usePromotionRepo(t, commits, tt.yanked...)
+			got, err := promotionSource(environments, tt.env, tt.component)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("promotionSource(%s, %s) = %+v, %v, want an error mentioning %s", tt.env, tt.component, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Tag != tt.want || got.Component != tt.component {
+				t.Errorf("promotionSource(%s, %s) = %s of %s, want %s", tt.env, tt.component, got.Tag, got.Component, tt.want)
+			}
+			for i, tags := range commits {
+				if containsTag(tags, tt.want) && got.Commit != hashes[i] {
+					t.Errorf("promotionSource() commit = %s, want %s", got.Commit, hashes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestYankedCommits(t *testing.T) {
+	tests := []struct {
+		name     string
+		releases []rtag.Release
+		want     map[string]bool
+	}{
+		{"none", nil, map[string]bool{}},
+		{"kept", releasesOf("r1@c1", "r2@c2"), map[string]bool{"c1": false, "c2": false}},
+		{"yanked", releasesOf("r1@c1", "r2@c2!"), map[string]bool{"c1": false, "c2": true}},
+		{"all releases of a commit yanked", releasesOf("r1@c1!", "r2@c1!"), map[string]bool{"c1": true}},
+		{"released again after the yank", releasesOf("r1@c1!", "r2@c1"), map[string]bool{"c1": false}},
+		{"yanked after another release", releasesOf("r1@c1", "r2@c1!"), map[string]bool{"c1": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := yankedCommits(tt.releases); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("yankedCommits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// pushRetryPolicy returns the retry policy of pushes. --retries takes
+// precedence over push_retries in the project config.
+func pushRetryPolicy() rtag.RetryPolicy {
+	policy := rtag.DefaultRetryPolicy
+	policy.Retries = pushRetries
+
+	if !rootCmd.PersistentFlags().Changed("retries") {
+		if config, err := loadProjectConfig(); err == nil {
+			policy.Retries = config.PushRetries
+		}
+	}
+	return policy
+}
+
+// printPushAttempts prints the attempt history of a push that was retried
+func printPushAttempts(result rtag.PushResult) {
+	if len(result.Attempts) < 2 {
+		return
+	}
+
+	fmt.Println(T().PushAttempts)
+	for i, attempt := range result.Attempts {
+		switch {
+		case attempt.Err == nil:
+			fmt.Printf(T().PushAttemptSucceeded+"\n", i+1, len(attempt.Refspecs))
+		case len(attempt.Retry) > 0:
+			fmt.Printf(T().PushAttemptRetrying+"\n", i+1, len(attempt.Retry), attempt.Delay, attempt.Err)
+		default:
+			fmt.Printf(T().PushAttemptFailed+"\n", i+1, attempt.Err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// rollbackAnnotationFormat is the annotation recorded on rollback tags
+const rollbackAnnotationFormat = "Rollback of %s to %s"
+
+func runRollback(cmd *cobra.Command, args []string) {
+	tag := args[0]
+	tags, err := readTags()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	if !containsTag(tags, tag) {
+		fmt.Printf(T().TagNotExistInFile+"\n", tag)
+		return
+	}
+
+	releases, err := listReleases(tag)
+	if err != nil {
+		fmt.Printf(T().ListReleasesFailed+"\n", err)
+		return
+	}
+
+	if len(releases) == 0 {
+		fmt.Printf(T().NoReleasesFound+"\n", tag)
+		return
+	}
+
+	current := releases[len(releases)-1]
+	target, err := selectRollbackTarget(releases, rollbackTo)
+	if err != nil {
+		fmt.Printf(T().RollbackFailed+"\n", err)
+		return
+	}
+
+	// The rollback is a release of its own, it cannot share the minute of another one
+	now := time.Now()
+	currentTime := now.Format(rtag.TimestampFormat)
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().RollbackFailed+"\n", err)
+		return
+	}
+	gitTag := project.Naming().Format(map[string]string{"timestamp": currentTime, "component": tag})
+	if exists, err := gitRepo().RefExists(runContext, "refs/tags/"+gitTag); err != nil {
+		fmt.Printf(T().RollbackFailed+"\n", err)
+		return
+	} else if exists {
+		fmt.Printf(T().RollbackFailed+"\n", fmt.Errorf(T().RollbackTagExists, gitTag))
+		return
+	}
+
+	report := runRelease(releaseRun{
+		Components: []string{tag},
+		Time:       now,
+		Commit:     target.Commit,
+		TagName: func(component string) string {
+			return gitTag
+		},
+		History: listAllReleases,
+		Create: func(config projectConfig, override string) ([]string, []string, bool) {
+			fmt.Printf(T().RollingBack+"\n", tag, current.Tag, target.Tag)
+
+			annotation := withFreezeOverride(fmt.Sprintf(rollbackAnnotationFormat, current.Tag, target.Tag), override)
+			if err := createTag(gitTag, target.Commit, rtag.TagOptions{Message: annotation}); err != nil {
+				fmt.Printf(T().CreateTagFailed+"\n", gitTag, err)
+				return nil, nil, false
+			}
+
+			refspecs := []string{rtag.TagRefspec(gitTag)}
+			if config.FloatingTags {
+				if refspec, ok := updateFloatingTag(tag, gitTag); ok {
+					refspecs = append(refspecs, refspec)
+				}
+			}
+			return []string{gitTag}, refspecs, true
+		},
+	})
+
+	if report.Pushed {
+		fmt.Printf(T().RollbackSuccess+"\n", tag, target.Tag, gitTag)
+	}
+}
+
+// selectRollbackTarget picks the release to roll back to. Without an explicit
+// choice it is the newest release that is not yanked and was made before the
+// current commit was first released. After a rollback the current release
+// re-releases an old commit, so the releases after its original are the ones
+// that were rolled back from and are skipped.
+func selectRollbackTarget(releases []rtag.Release, to string) (rtag.Release, error) {
+	current := releases[len(releases)-1]
+
+	if to != "" {
+		if to == current.Tag {
+			return rtag.Release{}, fmt.Errorf(T().RollbackTargetIsCurrent, to)
+		}
+		for _, r := range releases {
+			if r.Tag == to {
+				if r.Yanked {
+					return rtag.Release{}, fmt.Errorf(T().ReleaseIsYanked, to)
+				}
+				return r, nil
+			}
+		}
+		return rtag.Release{}, fmt.Errorf(T().ReleaseNotFound, to, current.Component)
+	}
+
+	original := len(releases) - 1
+	for i, r := range releases {
+		if r.Commit == current.Commit {
+			original = i
+			break
+		}
+	}
+
+	for i := original - 1; i >= 0; i-- {
+		if !releases[i].Yanked && releases[i].Commit != current.Commit {
+			return releases[i], nil
+		}
+	}
+
+	return rtag.Release{}, fmt.Errorf(T().NoPreviousRelease, current.Component)
+}
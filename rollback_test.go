@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// releasesOf returns releases of api given as "tag@commit", with a trailing
+// "!" for yanked ones, oldest first
+func releasesOf(specs ...string) []rtag.Release {
+	releases := make([]rtag.Release, len(specs))
+	for i, spec := range specs {
+		spec, yanked := strings.CutSuffix(spec, "!")
+		tag, commit, _ := strings.Cut(spec, "@")
+		releases[i] = rtag.Release{Tag: tag, Component: "api", Commit: commit, Yanked: yanked}
+	}
+	return releases
+}
+
+func TestSelectRollbackTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		releases []rtag.Release
+		to       string
+		want     string
+		wantErr  string // text expected in the error
+	}{
+		{"previous release", releasesOf("r1@c1", "r2@c2", "r3@c3"), "", "r2", ""},
+		{"skips yanked releases", releasesOf("r1@c1", "r2@c2!", "r3@c3"), "", "r1", ""},
+		{"skips releases of the current commit", releasesOf("r1@c1", "r2@c2", "r3@c2"), "", "r1", ""},
+		{"skips rolled back releases", releasesOf("r1@c1", "r2@c2", "r3@c3", "r4@c2"), "", "r1", ""},
+		{"skips a rolled back release of the target", releasesOf("r1@c1", "r2@c2", "r3@c1", "r4@c3"), "", "r3", ""},
+		{"no release before the first", releasesOf("r1@c1", "r2@c2", "r3@c3", "r4@c2", "r5@c1"), "", "", "api"},
+		{"single release", releasesOf("r1@c1"), "", "", "api"},
+		{"only yanked releases before", releasesOf("r1@c1!", "r2@c2!", "r3@c3"), "", "", "api"},
+		{"explicit release", releasesOf("r1@c1", "r2@c2", "r3@c3"), "r1", "r1", ""},
+		{"explicit rolled back release", releasesOf("r1@c1", "r2@c2", "r3@c3", "r4@c2"), "r3", "r3", ""},
+		{"explicit current release", releasesOf("r1@c1", "r2@c2"), "r2", "", "r2"},
+		{"explicit yanked release", releasesOf("r1@c1!", "r2@c2"), "r1", "", "r1"},
+		{"explicit unknown release", releasesOf("r1@c1", "r2@c2"), "r0", "", "r0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectRollbackTarget(tt.releases, tt.to)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("selectRollbackTarget() = %+v, %v, want an error mentioning %s", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Tag != tt.want {
+				t.Errorf("selectRollbackTarget() = %s, want %s", got.Tag, tt.want)
+			}
+		})
+	}
+}
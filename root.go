@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+var repoRootCache *string
+var currentGitBackend rtag.Backend
+
+// gitRepo returns the git backend for the current directory. RTAG_GIT_BACKEND
+// selects "exec" or "native", by default the git binary is used when it is
+// installed and the native backend otherwise.
+func gitRepo() rtag.Backend {
+	if currentGitBackend != nil {
+		return currentGitBackend
+	}
+
+	switch os.Getenv("RTAG_GIT_BACKEND") {
+	case "exec":
+		currentGitBackend = rtag.NewExecBackend("")
+	case "native":
+		currentGitBackend = rtag.NewNativeBackend(".")
+	default:
+		currentGitBackend = rtag.DefaultBackend("")
+	}
+	currentGitBackend = rtag.WithTimeout(currentGitBackend, gitTimeout)
+
+	return currentGitBackend
+}
+
+// openProject returns the rtag project of the current repository, naming
+// release tags after the tag_template of the project config
+func openProject() (*rtag.Project, error) {
+	config, err := loadProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []rtag.Option{rtag.WithBackend(gitRepo()), rtag.WithTagFile(rtagFilePath())}
+	if config.TagTemplate != "" {
+		opts = append(opts, rtag.WithNaming(config.TagTemplate))
+	}
+	project, err := rtag.New(projectRoot(), opts...)
+	if err != nil {
+		return nil, localizeError(err)
+	}
+	return project, nil
+}
+
+// repoRoot returns the top-level directory of the current git working tree.
+// Linked worktrees and submodules resolve to their own checkout, which is
+// where their .rtag file lives. It returns "" outside of a git repository.
+func repoRoot() string {
+	if repoRootCache != nil {
+		return *repoRootCache
+	}
+
+	root := ""
+	if dir, err := gitRepo().Root(runContext); err == nil {
+		root = dir
+	}
+
+	repoRootCache = &root
+	return root
+}
+
+// rtagFileOverride returns the .rtag path given with --file or RTAG_FILE, or ""
+func rtagFileOverride() string {
+	if rtagFileFlag != "" {
+		return rtagFileFlag
+	}
+	return os.Getenv("RTAG_FILE")
+}
+
+// rtagFilePath returns the path of the .rtag file: --file or RTAG_FILE when
+// set, otherwise .rtag in the repository root, falling back to the current directory
+func rtagFilePath() string {
+	if override := rtagFileOverride(); override != "" {
+		return override
+	}
+
+	if root := repoRoot(); root != "" {
+		return filepath.Join(root, rtag.TagFileName)
+	}
+
+	return rtag.TagFileName
+}
+
+// enterRepo changes the working directory to another repository and forgets
+// the cached root of the previous one
+func enterRepo(dir string) error {
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	repoRootCache = nil
+	currentGitBackend = nil
+	return nil
+}
+
+// projectRoot returns the directory containing the .rtag file, which is also
+// where the project config lives and where repository discovery starts
+func projectRoot() string {
+	return filepath.Dir(rtagFilePath())
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+// resolveSelectors expands command line selectors into tags from the .rtag file.
+// A selector is a tag name, a group reference such as @backend, or a glob
+// pattern such as 'worker-*'. The result keeps the order of first appearance
+// and contains each tag once.
+func resolveSelectors(content rtag.TagFile, selectors []string) ([]string, error) {
+	var resolved []string
+	seen := make(map[string]bool)
+	appendTag := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			resolved = append(resolved, tag)
+		}
+	}
+
+	for _, selector := range selectors {
+		switch {
+		case strings.HasPrefix(selector, "@"):
+			group, found := findGroup(content.Groups, strings.TrimPrefix(selector, "@"))
+			if !found {
+				return nil, fmt.Errorf(T().GroupNotExist, selector)
+			}
+			for _, tag := range group.Tags {
+				if !containsTag(content.Tags, tag) {
+					return nil, fmt.Errorf(T().GroupTagNotExist, selector, tag)
+				}
+				appendTag(tag)
+			}
+
+		case strings.ContainsAny(selector, "*?["):
+			matched := false
+			for _, tag := range content.Tags {
+				ok, err := path.Match(selector, tag)
+				if err != nil {
+					return nil, fmt.Errorf(T().InvalidPattern, selector, err)
+				}
+				if ok {
+					matched = true
+					appendTag(tag)
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf(T().NoTagsMatch, selector)
+			}
+
+		default:
+			if !containsTag(content.Tags, selector) {
+				return nil, fmt.Errorf(T().TagNotExist, selector)
+			}
+			appendTag(selector)
+		}
+	}
+
+	return resolved, nil
+}
+
+// findGroup looks up a group by name
+func findGroup(groups []rtag.Group, name string) (rtag.Group, bool) {
+	for _, group := range groups {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return rtag.Group{}, false
+}
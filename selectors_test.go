@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+)
+
+func TestResolveSelectors(t *testing.T) {
+	content := rtag.TagFile{
+		Tags: []string{"api", "web", "worker-mail", "worker-sms", "cron"},
+		Groups: []rtag.Group{
+			{Name: "backend", Tags: []string{"api", "worker-mail", "worker-sms"}},
+			{Name: "broken", Tags: []string{"api", "removed"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		selectors []string
+		want      []string
+		wantErr   string // text expected in the error
+	}{
+		{"tag", []string{"web"}, []string{"web"}, ""},
+		{"tags in the given order", []string{"cron", "api"}, []string{"cron", "api"}, ""},
+		{"group", []string{"@backend"}, []string{"api", "worker-mail", "worker-sms"}, ""},
+		{"pattern in file order", []string{"worker-*"}, []string{"worker-mail", "worker-sms"}, ""},
+		{"single character pattern", []string{"?pi"}, []string{"api"}, ""},
+		{"class pattern", []string{"[cw]*"}, []string{"web", "worker-mail", "worker-sms", "cron"}, ""},
+		{"duplicates once", []string{"worker-sms", "@backend", "worker-*", "api"}, []string{"worker-sms", "api", "worker-mail"}, ""},
+		{"nothing", nil, nil, ""},
+		{"unknown tag", []string{"api", "mobile"}, nil, "mobile"},
+		{"unknown group", []string{"@frontend"}, nil, "@frontend"},
+		{"group with an unknown tag", []string{"@broken"}, nil, "removed"},
+		{"pattern without match", []string{"mobile-*"}, nil, "mobile-*"},
+		{"invalid pattern", []string{"worker-[*"}, nil, "worker-[*"},
+		{"tag names are case sensitive", []string{"API"}, nil, "API"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSelectors(content, tt.selectors)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("resolveSelectors(%q) = %v, %v, want an error mentioning %s", tt.selectors, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveSelectors(%q) = %q, want %q", tt.selectors, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// trainTagPrefix is the prefix of the umbrella tags binding a release train
+const trainTagPrefix = "train"
+
+func runTrain(cmd *cobra.Command, args []string) {
+	group := strings.TrimPrefix(args[0], "@")
+
+	content, err := readTagFile()
+	if err != nil {
+		fmt.Printf(T().ReadTagsFailed+"\n", err)
+		return
+	}
+
+	tags, err := resolveSelectors(content, []string{"@" + group})
+	if err == nil {
+		tags, err = orderTags(content, tags)
+	}
+	if err != nil {
+		fmt.Printf(T().SelectTagsFailed+"\n", err)
+		return
+	}
+
+	commit, err := resolveCommit("HEAD")
+	if err != nil {
+		fmt.Printf(T().ResolveCommitFailed+"\n", "HEAD", err)
+		return
+	}
+
+	project, err := openProject()
+	if err != nil {
+		fmt.Printf(T().ReleaseFailed+"\n", err)
+		return
+	}
+	trainTemplate, err := prefixedTemplate(trainTagPrefix)
+	if err != nil {
+		fmt.Printf(T().ReleaseFailed+"\n", err)
+		return
+	}
+
+	now := time.Now()
+	currentTime := now.Format(rtag.TimestampFormat)
+	trainTag := trainTemplate.Format(map[string]string{"timestamp": currentTime, "component": group})
+	report := runRelease(releaseRun{
+		Components: tags,
+		Time:       now,
+		Commit:     commit,
+		TagName: func(component string) string {
+			return project.Naming().Format(map[string]string{"timestamp": currentTime, "component": component})
+		},
+		History: listAllReleases,
+		Create: func(config projectConfig, annotation string) ([]string, []string, bool) {
+			fmt.Printf(T().StartTrain+"\n", group, currentTime)
+
+			// A train is all or nothing, the tags of a failed attempt are
+			// removed so that it can simply be run again
+			created, refspecs := createReleaseTags(tags, now, config.FloatingTags, annotation)
+			if len(created) != len(tags) {
+				fmt.Printf(T().TrainIncomplete+"\n", group)
+				discardUnpushedTags()
+				return nil, nil, false
+			}
+
+			fmt.Printf(T().CreateGitTag+"\n", trainTag)
+			if err := createTag(trainTag, commit, rtag.TagOptions{Message: trainAnnotation(group, created, commit)}); err != nil {
+				fmt.Printf(T().CreateTagFailed+"\n", trainTag, err)
+				fmt.Printf(T().TrainIncomplete+"\n", group)
+				discardUnpushedTags()
+				return nil, nil, false
+			}
+			return append(created, trainTag), append(refspecs, rtag.TagRefspec(trainTag)), true
+		},
+	})
+
+	if report.Pushed {
+		fmt.Printf(T().TrainSuccess+"\n", group, trainTag)
+	} else if runContext.Err() == nil && len(unpushedTags) > 0 {
+		fmt.Printf(T().TrainNotPushed+"\n", group)
+		discardUnpushedTags()
+	}
+
+	if trainReportFile != "" {
+		writeReport(trainReportFile, report)
+	}
+}
+
+// trainAnnotation builds the umbrella tag annotation, listing one
+// "{release tag} {commit}" line per component after the subject line
+func trainAnnotation(group string, gitTags []string, commit string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Release train %s\n\n", group)
+	for _, gitTag := range gitTags {
+		fmt.Fprintf(&b, "%s %s\n", gitTag, commit)
+	}
+	return b.String()
+}
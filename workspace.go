@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+// defaultWorkspaceFile lists the repositories of a workspace, one path per line
+const defaultWorkspaceFile = ".rtag-workspace"
+
+// workspaceRepoResult is the outcome of a workspace command in one repository
+type workspaceRepoResult struct {
+	Path    string   `json:"path"`
+	Tags    []string `json:"tags,omitempty"`
+	Created []string `json:"created,omitempty"`
+	Pushed  bool     `json:"pushed"`
+	Error   string   `json:"error,omitempty"`
+
+	Hooks          []hookResult          `json:"hooks,omitempty"`
+	Notifications  []notifyResult        `json:"notifications,omitempty"`
+	HostedReleases []hostedReleaseResult `json:"hosted_releases,omitempty"`
+}
+
+// workspaceReport is the combined JSON report of a workspace push
+type workspaceReport struct {
+	Timestamp    string                `json:"timestamp"`
+	Repositories []workspaceRepoResult `json:"repositories"`
+}
+
+// readWorkspace returns the absolute repository paths listed in a workspace
+// file. Relative paths are resolved against the directory of the file, blank
+// lines and lines starting with # are ignored.
+func readWorkspace(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	base, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(base, line)
+		}
+		repos = append(repos, filepath.Clean(line))
+	}
+
+	return repos, scanner.Err()
+}
+
+// forEachWorkspaceRepo runs fn inside every repository of the workspace and
+// returns to the original directory afterwards
+func forEachWorkspaceRepo(fn func(repo string) error) ([]workspaceRepoResult, bool) {
+	if rtagFileOverride() != "" {
+		fmt.Println(T().WorkspaceFileOverride)
+		return nil, false
+	}
+
+	repos, err := readWorkspace(workspaceFile)
+	if err != nil {
+		fmt.Printf(T().ReadWorkspaceFailed+"\n", err)
+		return nil, false
+	}
+
+	if len(repos) == 0 {
+		fmt.Println(T().WorkspaceEmpty)
+		return nil, false
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf(T().ReadWorkspaceFailed+"\n", err)
+		return nil, false
+	}
+	defer enterRepo(cwd)
+
+	results := make([]workspaceRepoResult, 0, len(repos))
+	for _, repo := range repos {
+		// Ctrl-C stops before the next repository instead of failing each of them
+		if runContext.Err() != nil {
+			break
+		}
+
+		fmt.Printf("\n"+T().WorkspaceRepo+"\n", repo)
+		result := workspaceRepoResult{Path: repo}
+
+		if err := enterRepo(repo); err != nil {
+			result.Error = err.Error()
+		} else if err := fn(repo); err != nil {
+			result.Error = err.Error()
+		}
+
+		if result.Error != "" {
+			fmt.Printf(T().WorkspaceRepoFailed+"\n", repo, result.Error)
+		}
+		results = append(results, result)
+	}
+
+	return results, true
+}
+
+func runWorkspaceList(cmd *cobra.Command, args []string) {
+	forEachWorkspaceRepo(func(repo string) error {
+		content, err := readTagFile()
+		if err != nil {
+			return err
+		}
+
+		if len(content.Tags) == 0 {
+			fmt.Println(T().NoTagsFound)
+			return nil
+		}
+
+		for _, tag := range content.Tags {
+			fmt.Printf("  - %s\n", tag)
+		}
+		return nil
+	})
+}
+
+func runWorkspaceStatus(cmd *cobra.Command, args []string) {
+	forEachWorkspaceRepo(func(repo string) error {
+		branch, err := gitRepo().Branch(runContext)
+		if err != nil {
+			return err
+		}
+
+		changes, err := gitRepo().ChangedFiles(runContext)
+		if err != nil {
+			return err
+		}
+
+		if changes == 0 {
+			fmt.Printf(T().WorkspaceBranchClean+"\n", branch)
+		} else {
+			fmt.Printf(T().WorkspaceBranchDirty+"\n", branch, changes)
+		}
+
+		tags, err := readTags()
+		if err != nil {
+			return err
+		}
+
+		all, err := listAllReleases()
+		if err != nil {
+			return err
+		}
+
+		for _, tag := range tags {
+			releases := all[tag]
+			latest := "-"
+			for i := len(releases) - 1; i >= 0; i-- {
+				if !releases[i].Yanked {
+					latest = releases[i].Tag
+					break
+				}
+			}
+			fmt.Printf("  - %s: %s\n", tag, latest)
+		}
+		return nil
+	})
+}
+
+func runWorkspacePush(cmd *cobra.Command, args []string) {
+	if !pushAll {
+		fmt.Println(T().WorkspacePushRequiresAll)
+		return
+	}
+
+	// 所有仓库共用同一个时间戳
+	now := time.Now()
+	report := workspaceReport{Timestamp: now.Format(rtag.TimestampFormat)}
+
+	results, ok := forEachWorkspaceRepo(func(repo string) error {
+		content, err := readTagFile()
+		if err != nil {
+			return err
+		}
+
+		tags, err := orderTags(content, content.Tags)
+		if err != nil {
+			return err
+		}
+
+		if len(tags) == 0 {
+			fmt.Println(T().NoTagsFound)
+			return nil
+		}
+
+		release := releaseTags(tags, now)
+		report.Repositories = append(report.Repositories, workspaceRepoResult{Path: repo, Tags: tags, Created: release.Created, Pushed: release.Pushed, Hooks: release.Hooks, Notifications: release.Notifications, HostedReleases: release.HostedReleases})
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	// 合并每个仓库的推送结果与错误
+	report.Repositories = mergeWorkspaceResults(results, report.Repositories)
+
+	succeeded := 0
+	for _, result := range report.Repositories {
+		if result.Pushed {
+			succeeded++
+		}
+	}
+	fmt.Printf("\n"+T().WorkspacePushSummary+"\n", succeeded, len(report.Repositories), report.Timestamp)
+
+	if workspaceReportFile != "" {
+		writeReport(workspaceReportFile, report)
+	}
+}
+
+// mergeWorkspaceResults combines the per repository errors with the push
+// results, keeping the workspace order
+func mergeWorkspaceResults(results, pushes []workspaceRepoResult) []workspaceRepoResult {
+	byPath := make(map[string]workspaceRepoResult, len(pushes))
+	for _, push := range pushes {
+		byPath[push.Path] = push
+	}
+
+	merged := make([]workspaceRepoResult, 0, len(results))
+	for _, result := range results {
+		if push, ok := byPath[result.Path]; ok && result.Error == "" {
+			result = push
+		}
+		merged = append(merged, result)
+	}
+	return merged
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rushairer/rtag/pkg/rtag"
+	"github.com/spf13/cobra"
+)
+
+func runYank(cmd *cobra.Command, args []string) {
+	gitTag := args[0]
+	reason := strings.TrimSpace(yankReason)
+	if reason == "" {
+		fmt.Println(T().YankReasonRequired)
+		return
+	}
+
+	if !checkPush() {
+		return
+	}
+
+	if err := yankRelease(gitTag, reason); err != nil {
+		fmt.Printf(T().YankFailed+"\n", err)
+		return
+	}
+
+	fmt.Println(T().PushingTagsToRemote)
+	yankedRef := rtag.YankedRefPrefix + gitTag
+	if err := pushRefs([]string{yankedRef + ":" + yankedRef}); err != nil {
+		fmt.Printf(T().PushTagsFailed+"\n", err)
+		return
+	}
+
+	fmt.Printf(T().YankSuccess+"\n", gitTag)
+}
+
+func runHistory(cmd *cobra.Command, args []string) {
+	tag := args[0]
+	releases, err := listReleases(tag)
+	if err != nil {
+		fmt.Printf(T().ListReleasesFailed+"\n", err)
+		return
+	}
+
+	if len(releases) == 0 {
+		fmt.Printf(T().NoReleasesFound+"\n", tag)
+		return
+	}
+
+	fmt.Printf(T().ReleaseHistory+"\n", tag)
+	for i := len(releases) - 1; i >= 0; i-- {
+		r := releases[i]
+		if r.Yanked {
+			fmt.Printf("  - %s (%s) "+T().YankedMarker+"\n", r.Tag, shortCommit(r.Commit), r.YankReason)
+		} else {
+			fmt.Printf("  - %s (%s)\n", r.Tag, shortCommit(r.Commit))
+		}
+	}
+}
+
+func runLatest(cmd *cobra.Command, args []string) {
+	tag := args[0]
+	releases, err := listReleases(tag)
+	if err != nil {
+		fmt.Printf(T().ListReleasesFailed+"\n", err)
+		return
+	}
+
+	for i := len(releases) - 1; i >= 0; i-- {
+		if !releases[i].Yanked {
+			fmt.Println(releases[i].Tag)
+			return
+		}
+	}
+
+	fmt.Printf(T().NoReleasesFound+"\n", tag)
+}
+
+// yankRelease records a release tag as yanked with the given reason. A yank
+// recorded by an earlier run whose push failed is kept, so it is pushed again.
+func yankRelease(gitTag, reason string) error {
+	project, err := openProject()
+	if err != nil {
+		return err
+	}
+	if _, ok := project.Naming().Match(gitTag); !ok {
+		return fmt.Errorf(T().NotAReleaseTag, gitTag)
+	}
+
+	if _, err := resolveCommit("refs/tags/" + gitTag); err != nil {
+		return fmt.Errorf(T().ReleaseTagNotExist, gitTag)
+	}
+
+	fetchYanked()
+	yankedRef := rtag.YankedRefPrefix + gitTag
+	if exists, err := gitRepo().RefExists(runContext, yankedRef); err != nil {
+		return err
+	} else if exists {
+		remote, err := gitRepo().RemoteRefs(runContext, "origin", yankedRef)
+		if err != nil {
+			return err
+		}
+		if _, pushed := remote[yankedRef]; pushed {
+			return fmt.Errorf(T().ReleaseAlreadyYanked, gitTag)
+		}
+		fmt.Printf(T().YankNotPushed+"\n", gitTag)
+		return nil
+	}
+
+	// WriteBlobRef fails if another yank raced us
+	return gitRepo().WriteBlobRef(runContext, yankedRef, reason+"\n")
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}